@@ -13,9 +13,10 @@ import (
 
 	"github.com/drone/go-login/login"
 	"github.com/drone/go-login/login/bitbucket"
+	"github.com/drone/go-login/login/gitea"
+	"github.com/drone/go-login/login/gitee"
 	"github.com/drone/go-login/login/github"
 	"github.com/drone/go-login/login/gitlab"
-	"github.com/drone/go-login/login/gitee"
 	"github.com/drone/go-login/login/gogs"
 	"github.com/drone/go-login/login/logger"
 	"github.com/drone/go-login/login/stash"
@@ -50,10 +51,17 @@ func main() {
 
 	var middleware login.Middleware
 	switch *provider {
-	case "gogs", "gitea":
+	case "gogs":
 		middleware = &gogs.Config{
 			Login:  "/login/form",
 			Server: *providerURL,
+			Dumper: dumper,
+		}
+	case "gitea":
+		middleware = &gitea.PasswordConfig{
+			Login:  "/login/form",
+			Server: *providerURL,
+			Dumper: dumper,
 		}
 	case "gitlab":
 		middleware = &gitlab.Config{
@@ -61,6 +69,7 @@ func main() {
 			ClientSecret: *clientSecret,
 			RedirectURL:  *redirectURL,
 			Scope:        []string{"read_user", "api"},
+			Dumper:       dumper,
 		}
 	case "gitee":
 		middleware = &gitee.Config{
@@ -68,6 +77,7 @@ func main() {
 			ClientSecret: *clientSecret,
 			RedirectURL:  *redirectURL,
 			Scope:        []string{"user_info", "projects", "pull_requests", "hook"},
+			Dumper:       dumper,
 		}
 	case "github":
 		middleware = &github.Config{
@@ -82,6 +92,7 @@ func main() {
 			ClientID:     *clientID,
 			ClientSecret: *clientSecret,
 			RedirectURL:  *redirectURL,
+			Dumper:       dumper,
 		}
 	case "stash":
 		privateKey, err := stash.ParsePrivateKeyFile(*consumerRsa)
@@ -93,6 +104,7 @@ func main() {
 			CallbackURL: *redirectURL,
 			ConsumerKey: *consumerKey,
 			PrivateKey:  privateKey,
+			Dumper:      dumper,
 		}
 	}
 
@@ -133,6 +145,7 @@ var loginForm = `
 <form method="POST" action="/login">
 <input type="text" name="username" />
 <input type="password" name="password" />
+<input type="text" name="otp" placeholder="one-time password" />
 <input type="submit" />
 </form>
 `
@@ -170,4 +183,4 @@ func usage() {
   --redirect-url          oauth redirect url
   --address               http server address (:8080)
   --help                  display this help and exit`)
-}
\ No newline at end of file
+}
@@ -1,5 +1,7 @@
 module github.com/drone/go-login
 
+go 1.13
+
 require (
 	github.com/davecgh/go-spew v1.1.1
 	github.com/h2non/gock v1.0.9
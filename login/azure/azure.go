@@ -0,0 +1,93 @@
+// Copyright 2017 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package azure provides an Azure DevOps login provider.
+package azure
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/drone/go-login/login"
+	"github.com/drone/go-login/login/logger"
+	"github.com/drone/go-login/login/oauth2"
+)
+
+var (
+	_ login.Middleware   = (*Config)(nil)
+	_ login.Refresher    = (*Config)(nil)
+	_ login.Identifier   = (*Config)(nil)
+	_ login.Revoker      = (*Config)(nil)
+	_ login.Introspector = (*Config)(nil)
+)
+
+// Config configures the Azure DevOps auth provider. The
+// ClientID is the application id and the ClientSecret is
+// the client secret issued when the application is
+// registered.
+type Config struct {
+	Client       *http.Client
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Server       string
+	Scope        []string
+	Logger       logger.Logger
+	Dumper       logger.Dumper
+	StateStore   login.StateStore
+	FetchUser    bool
+}
+
+// Handler returns a http.Handler that runs h at the
+// completion of the Azure DevOps authorization flow. The
+// Azure DevOps authorization details are available to h
+// in the http.Request context.
+func (c *Config) Handler(h http.Handler) http.Handler {
+	return oauth2.Handler(h, c.config())
+}
+
+// Refresh refreshes the Azure DevOps authorization token.
+func (c *Config) Refresh(ctx context.Context, token *login.Token) (*login.Token, error) {
+	return c.config().Refresh(ctx, token)
+}
+
+// Revoke returns ErrUnsupported. Azure DevOps does not
+// provide a token revocation endpoint.
+func (c *Config) Revoke(ctx context.Context, token *login.Token) error {
+	return login.ErrUnsupported
+}
+
+// config returns the oauth2 configuration. Azure DevOps
+// uses the assertion response type, and exchanges the
+// authorization code using the JWT bearer grant.
+func (c *Config) config() *oauth2.Config {
+	server := normalizeAddress(c.Server)
+	conf := &oauth2.Config{
+		AuthStyle:        oauth2.AuthStyleAssertion,
+		ResponseType:     "Assertion",
+		Client:           c.Client,
+		ClientID:         c.ClientID,
+		ClientSecret:     c.ClientSecret,
+		RedirectURL:      c.RedirectURL,
+		AccessTokenURL:   server + "/oauth2/token",
+		AuthorizationURL: server + "/oauth2/authorize",
+		Scope:            c.Scope,
+		Logger:           c.Logger,
+		Dumper:           c.Dumper,
+		StateStore:       c.StateStore,
+		Server:           server,
+	}
+	if c.FetchUser {
+		conf.Identifier = c
+	}
+	return conf
+}
+
+func normalizeAddress(address string) string {
+	if address == "" {
+		return "https://app.vssps.visualstudio.com"
+	}
+	return strings.TrimSuffix(address, "/")
+}
@@ -0,0 +1,160 @@
+// Copyright 2017 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/drone/go-login/login"
+)
+
+func TestHandler(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.FormValue("grant_type") != "urn:ietf:params:oauth:grant-type:jwt-bearer",
+			r.FormValue("assertion") != "3584d83530557fdd1f46af8289938c8ef79f9dc5",
+			r.FormValue("client_assertion_type") != "urn:ietf:params:oauth:client-assertion-type:jwt-bearer",
+			r.FormValue("client_assertion") != "5012f6c60b2",
+			r.FormValue("redirect_uri") != "https://company.com/login":
+			w.WriteHeader(400)
+			return
+		}
+		w.Write([]byte(`{"access_token":"755bb80e5b","token_type":"jwt-bearer","expires_in":"3599","refresh_token":"e08f3fa43e"}`))
+	})
+	mux.HandleFunc("/_apis/profile/profiles/me", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer 755bb80e5b" {
+			w.WriteHeader(401)
+			return
+		}
+		json.NewEncoder(w).Encode(&profile{
+			ID:    "2b7e0a6b-b2a8-4a33-9c3b-a9f7ecbb2c7a",
+			Name:  "Jane Doe",
+			Email: "janedoe@example.com",
+		})
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	conf := &Config{
+		ClientID:     "3da54155991",
+		ClientSecret: "5012f6c60b2",
+		RedirectURL:  "https://company.com/login",
+		Server:       ts.URL,
+		Scope:        []string{"vso.code", "vso.profile"},
+		FetchUser:    true,
+	}
+
+	var user *login.User
+	var server string
+	var token *login.Token
+	h := conf.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := login.ErrorFrom(r.Context()); err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+		user = login.UserFrom(r.Context())
+		token = login.TokenFrom(r.Context())
+		server = login.ServerFrom(r.Context())
+	}))
+
+	// initiates the authorization flow.
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/login", nil))
+
+	location, err := url.Parse(w.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := location.Path, "/oauth2/authorize"; got != want {
+		t.Errorf("Want redirect to %s, got %s", want, got)
+	}
+	if got, want := location.Query().Get("response_type"), "Assertion"; got != want {
+		t.Errorf("Want response_type %s, got %s", want, got)
+	}
+	if got, want := location.Query().Get("scope"), "vso.code vso.profile"; got != want {
+		t.Errorf("Want scope %q, got %q", want, got)
+	}
+
+	// completes the authorization flow.
+	cookies := w.Result().Cookies()
+	w = httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/login?code=3584d83530557fdd1f46af8289938c8ef79f9dc5&state="+location.Query().Get("state"), nil)
+	for _, c := range cookies {
+		r.AddCookie(c)
+	}
+	h.ServeHTTP(w, r)
+
+	if token == nil {
+		t.Fatalf("Expect token in context")
+	}
+	if got, want := token.Access, "755bb80e5b"; got != want {
+		t.Errorf("Want access token %s, got %s", want, got)
+	}
+	if got, want := token.Refresh, "e08f3fa43e"; got != want {
+		t.Errorf("Want refresh token %s, got %s", want, got)
+	}
+	if token.Expires.IsZero() {
+		t.Errorf("Want token expiry")
+	}
+	if got, want := server, ts.URL; got != want {
+		t.Errorf("Want server %s, got %s", want, got)
+	}
+	if user == nil {
+		t.Fatalf("Expect user in context")
+	}
+	if got, want := user.Login, "janedoe@example.com"; got != want {
+		t.Errorf("Want login %s, got %s", want, got)
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.FormValue("grant_type") != "refresh_token",
+			r.FormValue("assertion") != "e08f3fa43e",
+			r.FormValue("client_assertion") != "5012f6c60b2":
+			w.WriteHeader(400)
+			w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+		w.Write([]byte(`{"access_token":"9b2b4b1c7f","token_type":"jwt-bearer","expires_in":"3599","refresh_token":"7c9d1e4a2f"}`))
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	conf := &Config{
+		ClientSecret: "5012f6c60b2",
+		Server:       ts.URL,
+	}
+	token, err := conf.Refresh(context.Background(), &login.Token{Access: "755bb80e5b", Refresh: "e08f3fa43e"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := token.Access, "9b2b4b1c7f"; got != want {
+		t.Errorf("Want access token %s, got %s", want, got)
+	}
+
+	_, err = conf.Refresh(context.Background(), &login.Token{Refresh: "a5f1bd0c38"})
+	if !errors.Is(err, login.ErrTokenExchange) {
+		t.Errorf("Want ErrTokenExchange, got %v", err)
+	}
+}
+
+func TestNilToken(t *testing.T) {
+	c := &Config{}
+	if _, err := c.Identify(context.Background(), nil); err != login.ErrToken {
+		t.Errorf("Want error %s, got %v", login.ErrToken, err)
+	}
+	if _, err := c.Introspect(context.Background(), nil); err != login.ErrToken {
+		t.Errorf("Want error %s, got %v", login.ErrToken, err)
+	}
+}
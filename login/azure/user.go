@@ -0,0 +1,59 @@
+// Copyright 2017 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package azure
+
+import (
+	"context"
+
+	"github.com/drone/go-login/login"
+	"github.com/drone/go-login/login/internal/api"
+)
+
+type profile struct {
+	ID    string `json:"id"`
+	Alias string `json:"publicAlias"`
+	Name  string `json:"displayName"`
+	Email string `json:"emailAddress"`
+}
+
+// Identify returns the Azure DevOps user that authorized
+// the token. Azure DevOps users do not have a username,
+// so the email address is used as the login.
+func (c *Config) Identify(ctx context.Context, token *login.Token) (*login.User, error) {
+	if token == nil {
+		return nil, login.ErrToken
+	}
+	server := normalizeAddress(c.Server)
+	out := new(profile)
+	err := c.api(token).Get(ctx, server+"/_apis/profile/profiles/me?api-version=7.1", out)
+	if err != nil {
+		return nil, api.Wrap(login.ErrIdentity, err)
+	}
+	user := &login.User{
+		ID:    out.ID,
+		Login: out.Email,
+		Name:  out.Name,
+		Email: out.Email,
+		Host:  api.Host(server),
+	}
+	if user.Login == "" {
+		user.Login = out.Alias
+	}
+	return user, nil
+}
+
+// Introspect returns the Azure DevOps token details.
+func (c *Config) Introspect(ctx context.Context, token *login.Token) (*login.TokenInfo, error) {
+	return api.Introspect(c.Identify(ctx, token))
+}
+
+// api returns an api client authorized with the token.
+func (c *Config) api(token *login.Token) *api.Client {
+	return &api.Client{
+		Client:    c.Client,
+		Dumper:    c.Dumper,
+		Authorize: api.Bearer(token.Access),
+	}
+}
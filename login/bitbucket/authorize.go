@@ -0,0 +1,45 @@
+// Copyright 2017 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bitbucket
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/drone/go-login/login"
+	"github.com/drone/go-login/login/internal/api"
+)
+
+type permissions struct {
+	Values []struct {
+		Permission string `json:"permission"`
+	} `json:"values"`
+}
+
+// Authorize returns an error if the user that authorized
+// the token is not a member of at least one of the
+// workspaces. Workspaces are identified by slug.
+func (c *Config) Authorize(ctx context.Context, token *login.Token) error {
+	client := c.api(token)
+	server := apiAddress(normalizeAddress(c.Server))
+	for _, workspace := range c.Workspaces {
+		query := url.Values{
+			"q": {fmt.Sprintf("workspace.slug=%q", workspace)},
+		}
+		out := new(permissions)
+		err := client.Get(ctx, server+"/2.0/user/permissions/workspaces?"+query.Encode(), out)
+		if err != nil {
+			return api.Wrap(login.ErrNotAuthorized, err)
+		}
+		if len(out.Values) != 0 {
+			return nil
+		}
+	}
+	return &login.Error{
+		Kind:        login.ErrNotAuthorized,
+		Description: "User is not a member of the required workspaces",
+	}
+}
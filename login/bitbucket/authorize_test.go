@@ -0,0 +1,71 @@
+// Copyright 2017 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bitbucket
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/drone/go-login/login"
+	"github.com/drone/go-login/login/internal/api"
+	"github.com/h2non/gock"
+)
+
+func TestAuthorize(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://api.bitbucket.org").
+		Get("/2.0/user/permissions/workspaces").
+		MatchParam("q", `workspace.slug="drone"`).
+		MatchHeader("Authorization", "Bearer 755bb80e5b").
+		Reply(200).
+		JSON(map[string]interface{}{
+			"values": []map[string]string{{"permission": "member"}},
+		})
+
+	gock.New("https://api.bitbucket.org").
+		Get("/2.0/user/permissions/workspaces").
+		MatchParam("q", `workspace.slug="atlassian"`).
+		Reply(200).
+		JSON(map[string]interface{}{
+			"values": []map[string]string{},
+		})
+
+	c := &Config{Workspaces: []string{"atlassian", "drone"}}
+	err := c.Authorize(context.Background(), &login.Token{Access: "755bb80e5b"})
+	if err != nil {
+		t.Errorf("Want no error, got %v", err)
+	}
+
+	gock.New("https://api.bitbucket.org").
+		Get("/2.0/user/permissions/workspaces").
+		Reply(200).
+		JSON(map[string]interface{}{
+			"values": []map[string]string{},
+		})
+
+	c = &Config{Workspaces: []string{"atlassian"}}
+	err = c.Authorize(context.Background(), &login.Token{Access: "755bb80e5b"})
+	if !errors.Is(err, login.ErrNotAuthorized) {
+		t.Errorf("Want error %v, got %v", login.ErrNotAuthorized, err)
+	}
+
+	// the workspace check fails for reasons other than
+	// non-membership, and the cause is returned.
+	gock.New("https://api.bitbucket.org").
+		Get("/2.0/user/permissions/workspaces").
+		Reply(401)
+
+	c = &Config{Workspaces: []string{"atlassian", "drone"}}
+	err = c.Authorize(context.Background(), &login.Token{Access: "755bb80e5b"})
+	if !errors.Is(err, login.ErrNotAuthorized) {
+		t.Errorf("Want error %v, got %v", login.ErrNotAuthorized, err)
+	}
+	var e *api.Error
+	if !errors.As(err, &e) || e.Status != 401 {
+		t.Errorf("Want status 401 cause, got %v", err)
+	}
+}
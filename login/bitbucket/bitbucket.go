@@ -5,17 +5,22 @@
 package bitbucket
 
 import (
+	"context"
 	"net/http"
+	"strings"
 
 	"github.com/drone/go-login/login"
-	"github.com/drone/go-login/login/internal/oauth2"
+	"github.com/drone/go-login/login/logger"
+	"github.com/drone/go-login/login/oauth2"
 )
 
-var _ login.Middleware = (*Config)(nil)
-
-const (
-	accessTokenURL   = "https://bitbucket.org/site/oauth2/access_token"
-	authorizationURL = "https://bitbucket.org/site/oauth2/authorize"
+var (
+	_ login.Middleware   = (*Config)(nil)
+	_ login.Refresher    = (*Config)(nil)
+	_ login.Identifier   = (*Config)(nil)
+	_ login.Revoker      = (*Config)(nil)
+	_ login.Introspector = (*Config)(nil)
+	_ login.Authorizer   = (*Config)(nil)
 )
 
 // Config configures a Bitbucket auth provider.
@@ -24,6 +29,26 @@ type Config struct {
 	ClientID     string
 	ClientSecret string
 	RedirectURL  string
+	Scope        []string
+	Logger       logger.Logger
+	Dumper       logger.Dumper
+	PKCE         bool
+	StateStore   login.StateStore
+	FetchUser    bool
+	Workspaces   []string
+
+	// Server is the Bitbucket address. If empty, the
+	// address defaults to https://bitbucket.org. Any
+	// other address, such as a local stand-in used in
+	// tests, serves the oauth2 and api endpoints.
+	Server string
+
+	// AuthParams are additional authorization parameters.
+	AuthParams map[string]string
+
+	// RequestParams are the login request query parameters
+	// copied to the authorization request.
+	RequestParams []string
 }
 
 // Handler returns a http.Handler that runs h at the
@@ -31,12 +56,62 @@ type Config struct {
 // authorization details are available to h in the
 // http.Request context.
 func (c *Config) Handler(h http.Handler) http.Handler {
-	return oauth2.Handler(h, &oauth2.Config{
+	return oauth2.Handler(h, c.config())
+}
+
+// Refresh refreshes the Bitbucket authorization token.
+// Bitbucket may rotate the refresh token, in which case the
+// returned token includes the new refresh token.
+func (c *Config) Refresh(ctx context.Context, token *login.Token) (*login.Token, error) {
+	return c.config().Refresh(ctx, token)
+}
+
+// Revoke returns ErrUnsupported. Bitbucket does not provide
+// a token revocation endpoint.
+func (c *Config) Revoke(ctx context.Context, token *login.Token) error {
+	return login.ErrUnsupported
+}
+
+// config returns the oauth2 configuration.
+func (c *Config) config() *oauth2.Config {
+	server := normalizeAddress(c.Server)
+	conf := &oauth2.Config{
 		Client:           c.Client,
 		ClientID:         c.ClientID,
 		ClientSecret:     c.ClientSecret,
 		RedirectURL:      c.RedirectURL,
-		AccessTokenURL:   accessTokenURL,
-		AuthorizationURL: authorizationURL,
-	})
+		AccessTokenURL:   server + "/site/oauth2/access_token",
+		AuthorizationURL: server + "/site/oauth2/authorize",
+		Scope:            c.Scope,
+		Logger:           c.Logger,
+		Dumper:           c.Dumper,
+		PKCE:             c.PKCE,
+		StateStore:       c.StateStore,
+		Server:           server,
+		AuthParams:       c.AuthParams,
+		RequestParams:    c.RequestParams,
+	}
+	if c.FetchUser {
+		conf.Identifier = c
+	}
+	if len(c.Workspaces) != 0 {
+		conf.Authorizer = c
+	}
+	return conf
+}
+
+func normalizeAddress(address string) string {
+	if address == "" {
+		return "https://bitbucket.org"
+	}
+	return strings.TrimSuffix(address, "/")
+}
+
+// apiAddress returns the Bitbucket api address for the
+// server address.
+func apiAddress(server string) string {
+	if server == "https://bitbucket.org" {
+		return "https://api.bitbucket.org"
+	}
+	return server
 }
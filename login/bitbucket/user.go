@@ -0,0 +1,84 @@
+// Copyright 2017 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bitbucket
+
+import (
+	"context"
+
+	"github.com/drone/go-login/login"
+	"github.com/drone/go-login/login/internal/api"
+)
+
+type user struct {
+	UUID     string `json:"uuid"`
+	Username string `json:"username"`
+	Nickname string `json:"nickname"`
+	Name     string `json:"display_name"`
+	Links    struct {
+		Avatar struct {
+			Href string `json:"href"`
+		} `json:"avatar"`
+	} `json:"links"`
+}
+
+type emails struct {
+	Values []struct {
+		Email     string `json:"email"`
+		Primary   bool   `json:"is_primary"`
+		Confirmed bool   `json:"is_confirmed"`
+	} `json:"values"`
+}
+
+// Identify returns the Bitbucket user that authorized the
+// token.
+func (c *Config) Identify(ctx context.Context, token *login.Token) (*login.User, error) {
+	if token == nil {
+		return nil, login.ErrToken
+	}
+	client := c.api(token)
+	server := normalizeAddress(c.Server)
+	out := new(user)
+	err := client.Get(ctx, apiAddress(server)+"/2.0/user", out)
+	if err != nil {
+		return nil, api.Wrap(login.ErrIdentity, err)
+	}
+	username := out.Username
+	if username == "" {
+		username = out.Nickname
+	}
+	// the email address is not included in the user
+	// resource. This requires the email scope and is
+	// skipped on error.
+	var email string
+	list := new(emails)
+	client.Get(ctx, apiAddress(server)+"/2.0/user/emails", list)
+	for _, v := range list.Values {
+		if v.Primary && v.Confirmed {
+			email = v.Email
+		}
+	}
+	return &login.User{
+		ID:     out.UUID,
+		Login:  username,
+		Name:   out.Name,
+		Email:  email,
+		Avatar: out.Links.Avatar.Href,
+		Host:   api.Host(server),
+	}, nil
+}
+
+// Introspect returns the Bitbucket token details.
+func (c *Config) Introspect(ctx context.Context, token *login.Token) (*login.TokenInfo, error) {
+	return api.Introspect(c.Identify(ctx, token))
+}
+
+// api returns an api client authorized with the token.
+func (c *Config) api(token *login.Token) *api.Client {
+	return &api.Client{
+		Client:    c.Client,
+		Dumper:    c.Dumper,
+		Authorize: api.Bearer(token.Access),
+	}
+}
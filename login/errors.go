@@ -0,0 +1,130 @@
+// Copyright 2017 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package login
+
+import "errors"
+
+var (
+	// ErrAccessDenied indicates the user denied the
+	// authorization request.
+	ErrAccessDenied = errors.New("Access denied")
+
+	// ErrAuthorization indicates the authorization server
+	// rejected the authorization request.
+	ErrAuthorization = errors.New("Authorization failed")
+
+	// ErrState indicates the state is invalid.
+	ErrState = errors.New("Invalid state")
+
+	// ErrTokenExchange indicates the authorization server
+	// did not issue a token in exchange for the
+	// authorization grant.
+	ErrTokenExchange = errors.New("Token exchange failed")
+
+	// ErrIDToken indicates the OpenID Connect id_token is
+	// missing or invalid.
+	ErrIDToken = errors.New("Invalid id token")
+
+	// ErrUnreachable indicates the authorization server
+	// could not be reached.
+	ErrUnreachable = errors.New("Provider unreachable")
+
+	// ErrInvalidCredentials indicates the authorization
+	// server rejected the user credentials.
+	ErrInvalidCredentials = errors.New("Invalid credentials")
+
+	// ErrOTPRequired indicates the user account requires
+	// a one-time password in addition to the username and
+	// password.
+	ErrOTPRequired = errors.New("One-time password required")
+
+	// ErrScope indicates the token was not granted all
+	// of the requested scopes, for example because the
+	// user narrowed the scopes on the consent screen.
+	ErrScope = errors.New("Insufficient scope")
+
+	// ErrIdentity indicates the authenticated user could
+	// not be retrieved from the provider.
+	ErrIdentity = errors.New("Cannot identify user")
+
+	// ErrRevocation indicates the provider failed to
+	// revoke the token.
+	ErrRevocation = errors.New("Token revocation failed")
+
+	// ErrIntrospection indicates the provider failed to
+	// return the token details.
+	ErrIntrospection = errors.New("Token introspection failed")
+
+	// ErrToken indicates the token is missing.
+	ErrToken = errors.New("Missing token")
+
+	// ErrUnsupported indicates the operation is not
+	// supported by the provider.
+	ErrUnsupported = errors.New("Operation not supported")
+
+	// ErrNotAuthorized indicates the authenticated user
+	// does not satisfy the authorization policy, such as
+	// membership in a required organization.
+	ErrNotAuthorized = errors.New("Not authorized")
+)
+
+// Error represents a failed login. The Kind is one of the
+// package error values, which can be tested using
+// errors.Is, and the underlying cause is available using
+// errors.Unwrap.
+type Error struct {
+	// Kind is the category of the error.
+	Kind error
+
+	// Code is the error code returned by the provider.
+	Code string
+
+	// Description is the human-readable description of
+	// the error returned by the provider.
+	Description string
+
+	// URI identifies a human-readable web page with
+	// information about the error.
+	URI string
+
+	// Err is the underlying error.
+	Err error
+}
+
+// Error returns the string representation of the login
+// error.
+func (e *Error) Error() string {
+	var s string
+	if e.Kind != nil {
+		s = e.Kind.Error()
+	}
+	var detail string
+	switch {
+	case e.Description != "":
+		detail = e.Description
+	case e.Code != "":
+		detail = e.Code
+	case e.Err != nil:
+		detail = e.Err.Error()
+	}
+	switch {
+	case s == "":
+		return detail
+	case detail == "":
+		return s
+	default:
+		return s + ": " + detail
+	}
+}
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the target matches the error kind.
+func (e *Error) Is(target error) bool {
+	return e.Kind != nil && e.Kind == target
+}
@@ -0,0 +1,68 @@
+// Copyright 2017 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package login
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestError(t *testing.T) {
+	cause := errors.New("connection refused")
+	err := error(&Error{
+		Kind: ErrUnreachable,
+		Err:  cause,
+	})
+	if !errors.Is(err, ErrUnreachable) {
+		t.Errorf("Expect error is ErrUnreachable")
+	}
+	if errors.Is(err, ErrAccessDenied) {
+		t.Errorf("Expect error is not ErrAccessDenied")
+	}
+	if !errors.Is(err, cause) {
+		t.Errorf("Expect error wraps the cause")
+	}
+	if got, want := err.Error(), "Provider unreachable: connection refused"; got != want {
+		t.Errorf("Want error message %q, got %q", want, got)
+	}
+
+	var e *Error
+	if !errors.As(err, &e) {
+		t.Errorf("Expect error as *Error")
+	}
+}
+
+func TestErrorMessage(t *testing.T) {
+	tests := []struct {
+		err  *Error
+		want string
+	}{
+		{
+			err:  &Error{Kind: ErrAccessDenied},
+			want: "Access denied",
+		},
+		{
+			err:  &Error{Kind: ErrAccessDenied, Code: "access_denied"},
+			want: "Access denied: access_denied",
+		},
+		{
+			err: &Error{
+				Kind:        ErrAccessDenied,
+				Code:        "access_denied",
+				Description: "The user has denied your application access.",
+			},
+			want: "Access denied: The user has denied your application access.",
+		},
+		{
+			err:  &Error{Err: errors.New("Not Found")},
+			want: "Not Found",
+		},
+	}
+	for _, test := range tests {
+		if got := test.err.Error(); got != test.want {
+			t.Errorf("Want error message %q, got %q", test.want, got)
+		}
+	}
+}
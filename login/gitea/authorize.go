@@ -0,0 +1,43 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/drone/go-login/login"
+	"github.com/drone/go-login/login/internal/api"
+)
+
+// Authorize returns an error if the user that authorized
+// the token is not a member of at least one of the
+// organizations.
+func (c *Config) Authorize(ctx context.Context, token *login.Token) error {
+	server := normalizeAddress(c.Server)
+	client := c.api(token)
+	u := new(user)
+	err := client.Get(ctx, server+"/api/v1/user", u)
+	if err != nil {
+		return api.Wrap(login.ErrNotAuthorized, err)
+	}
+	for _, org := range c.Organizations {
+		path := fmt.Sprintf("%s/api/v1/orgs/%s/members/%s", server, url.PathEscape(org), url.PathEscape(u.Login))
+		err := client.Get(ctx, path, nil)
+		if e, ok := err.(*api.Error); ok && e.Status == http.StatusNotFound {
+			continue
+		}
+		if err != nil {
+			return api.Wrap(login.ErrNotAuthorized, err)
+		}
+		return nil
+	}
+	return &login.Error{
+		Kind:        login.ErrNotAuthorized,
+		Description: "User is not a member of the required organizations",
+	}
+}
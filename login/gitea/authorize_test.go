@@ -0,0 +1,68 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/drone/go-login/login"
+	"github.com/drone/go-login/login/internal/api"
+	"github.com/h2non/gock"
+)
+
+func TestAuthorize(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://try.gitea.io").
+		Get("/api/v1/user").
+		MatchHeader("Authorization", "Bearer 755bb80e5b").
+		Persist().
+		Reply(200).
+		JSON(&user{ID: 1, Login: "janedoe"})
+
+	gock.New("https://try.gitea.io").
+		Get("/api/v1/orgs/drone/members/janedoe").
+		Persist().
+		Reply(204)
+
+	gock.New("https://try.gitea.io").
+		Get("/api/v1/orgs/private/members/janedoe").
+		Persist().
+		Reply(403)
+
+	gock.New("https://try.gitea.io").
+		Get("/api/v1/orgs/").
+		Persist().
+		Reply(404)
+
+	tests := []struct {
+		orgs   []string
+		err    error
+		status int
+	}{
+		{orgs: []string{"drone"}},
+		{orgs: []string{"gitea", "drone"}},
+		{orgs: []string{"gitea"}, err: login.ErrNotAuthorized},
+		// the membership check fails for reasons other than
+		// non-membership, and the cause is returned.
+		{orgs: []string{"private", "drone"}, err: login.ErrNotAuthorized, status: 403},
+	}
+	for _, test := range tests {
+		c := &Config{Organizations: test.orgs}
+		err := c.Authorize(context.Background(), &login.Token{Access: "755bb80e5b"})
+		if test.err == nil && err != nil {
+			t.Errorf("Want no error for %v, got %v", test.orgs, err)
+		}
+		if test.err != nil && !errors.Is(err, test.err) {
+			t.Errorf("Want error %v for %v, got %v", test.err, test.orgs, err)
+		}
+		var e *api.Error
+		if test.status != 0 && (!errors.As(err, &e) || e.Status != test.status) {
+			t.Errorf("Want status %d cause for %v, got %v", test.status, test.orgs, err)
+		}
+	}
+}
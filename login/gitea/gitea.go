@@ -5,26 +5,39 @@
 package gitea
 
 import (
+	"context"
 	"net/http"
 	"strings"
 
 	"github.com/drone/go-login/login"
-	"github.com/drone/go-login/login/internal/oauth2"
 	"github.com/drone/go-login/login/logger"
+	"github.com/drone/go-login/login/oauth2"
 )
 
-var _ login.Middleware = (*Config)(nil)
+var (
+	_ login.Middleware       = (*Config)(nil)
+	_ login.Refresher        = (*Config)(nil)
+	_ login.Identifier       = (*Config)(nil)
+	_ login.Revoker          = (*Config)(nil)
+	_ login.Introspector     = (*Config)(nil)
+	_ login.Authorizer       = (*Config)(nil)
+	_ login.DeviceAuthorizer = (*Config)(nil)
+)
 
 // Config configures a GitHub authorization provider.
 type Config struct {
-	Client       *http.Client
-	ClientID     string
-	ClientSecret string
-	Server       string
-	Scope        []string
-	Logger       logger.Logger
-	Dumper       logger.Dumper
-	RedirectURL  string
+	Client        *http.Client
+	ClientID      string
+	ClientSecret  string
+	Server        string
+	Scope         []string
+	Logger        logger.Logger
+	Dumper        logger.Dumper
+	RedirectURL   string
+	PKCE          bool
+	StateStore    login.StateStore
+	FetchUser     bool
+	Organizations []string
 }
 
 // Handler returns a http.Handler that runs h at the
@@ -32,18 +45,58 @@ type Config struct {
 // authorization details are available to h in the
 // http.Request context.
 func (c *Config) Handler(h http.Handler) http.Handler {
+	return oauth2.Handler(h, c.config())
+}
+
+// Refresh refreshes the Gitea authorization token.
+func (c *Config) Refresh(ctx context.Context, token *login.Token) (*login.Token, error) {
+	return c.config().Refresh(ctx, token)
+}
+
+// Revoke returns ErrUnsupported. Gitea does not provide an
+// oauth2 token revocation endpoint, and oauth2 grants can
+// only be revoked by the user in the Gitea settings.
+func (c *Config) Revoke(ctx context.Context, token *login.Token) error {
+	return login.ErrUnsupported
+}
+
+// DeviceCode returns ErrUnsupported. Gitea does not
+// provide an oauth2 device authorization endpoint.
+func (c *Config) DeviceCode(ctx context.Context) (*login.DeviceCode, error) {
+	return nil, login.ErrUnsupported
+}
+
+// DeviceToken returns ErrUnsupported. Gitea does not
+// provide an oauth2 device authorization endpoint.
+func (c *Config) DeviceToken(ctx context.Context, code *login.DeviceCode) (*login.Token, error) {
+	return nil, login.ErrUnsupported
+}
+
+// config returns the oauth2 configuration.
+func (c *Config) config() *oauth2.Config {
 	server := normalizeAddress(c.Server)
-	return oauth2.Handler(h, &oauth2.Config{
-		BasicAuthOff:     true,
+	conf := &oauth2.Config{
+		AuthStyle:        oauth2.AuthStyleParams,
 		Client:           c.Client,
 		ClientID:         c.ClientID,
 		ClientSecret:     c.ClientSecret,
 		AccessTokenURL:   server + "/login/oauth/access_token",
 		AuthorizationURL: server + "/login/oauth/authorize",
+		Scope:            c.Scope,
 		Logger:           c.Logger,
 		Dumper:           c.Dumper,
 		RedirectURL:      c.RedirectURL,
-	})
+		PKCE:             c.PKCE,
+		StateStore:       c.StateStore,
+		Server:           server,
+	}
+	if c.FetchUser {
+		conf.Identifier = c
+	}
+	if len(c.Organizations) != 0 {
+		conf.Authorizer = c
+	}
+	return conf
 }
 
 func normalizeAddress(address string) string {
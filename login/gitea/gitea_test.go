@@ -0,0 +1,24 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConfig(t *testing.T) {
+	conf := &Config{
+		Server: "https://gitea.company.com/",
+		Scope:  []string{"read:user", "read:organization"},
+	}
+	c := conf.config()
+	if got, want := c.Scope, conf.Scope; !reflect.DeepEqual(got, want) {
+		t.Errorf("Want scope %q, got %q", want, got)
+	}
+	if got, want := c.Server, "https://gitea.company.com"; got != want {
+		t.Errorf("Want server %s, got %s", want, got)
+	}
+}
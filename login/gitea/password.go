@@ -0,0 +1,207 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/drone/go-login/login"
+	"github.com/drone/go-login/login/internal/api"
+	"github.com/drone/go-login/login/internal/password"
+	"github.com/drone/go-login/login/logger"
+)
+
+var (
+	_ login.Middleware   = (*PasswordConfig)(nil)
+	_ login.Identifier   = (*PasswordConfig)(nil)
+	_ login.Revoker      = (*PasswordConfig)(nil)
+	_ login.Introspector = (*PasswordConfig)(nil)
+)
+
+// pageSize is the number of tokens requested per page.
+// Gitea caps the page size at 50 items by default.
+const pageSize = 50
+
+// default scopes granted to the access token.
+var defaultScopes = []string{"read:user", "read:organization", "write:repository"}
+
+// PasswordConfig configures the Gitea username and
+// password login middleware. The credentials are
+// exchanged for a scoped access token.
+type PasswordConfig struct {
+	Label     string
+	Login     string
+	Server    string
+	Scopes    []string
+	Client    *http.Client
+	Logger    logger.Logger
+	Dumper    logger.Dumper
+	FetchUser bool
+}
+
+// Handler returns a http.Handler that runs h at the
+// completion of the Gitea login flow. The access token
+// is available to h in the http.Request context.
+func (c *PasswordConfig) Handler(h http.Handler) http.Handler {
+	v := &password.Handler{
+		Next:   h,
+		Name:   "gitea",
+		Login:  c.Login,
+		Mint:   c.minter().mint,
+		Logger: c.Logger,
+	}
+	if c.FetchUser {
+		v.Identifier = c
+	}
+	return v
+}
+
+// minter returns the access token minter.
+func (c *PasswordConfig) minter() *minter {
+	v := &minter{
+		label:  c.Label,
+		server: normalizeAddress(c.Server),
+		scopes: c.Scopes,
+		client: c.Client,
+		dumper: c.Dumper,
+	}
+	if v.label == "" {
+		v.label = "default"
+	}
+	if len(v.scopes) == 0 {
+		v.scopes = defaultScopes
+	}
+	return v
+}
+
+// Revoke returns ErrUnsupported. The Gitea token endpoint,
+// /api/v1/users/{user}/tokens/{id}, only accepts basic
+// auth with the username and password, which are not
+// available after login. The token is deleted and
+// replaced the next time the user logs in.
+func (c *PasswordConfig) Revoke(ctx context.Context, token *login.Token) error {
+	return login.ErrUnsupported
+}
+
+// Identify returns the Gitea user that owns the access
+// token.
+func (c *PasswordConfig) Identify(ctx context.Context, token *login.Token) (*login.User, error) {
+	if token == nil {
+		return nil, login.ErrToken
+	}
+	client := &api.Client{
+		Client:    c.Client,
+		Dumper:    c.Dumper,
+		Authorize: api.Token(token.Access),
+	}
+	return identify(ctx, client, normalizeAddress(c.Server))
+}
+
+// Introspect returns the Gitea token details.
+func (c *PasswordConfig) Introspect(ctx context.Context, token *login.Token) (*login.TokenInfo, error) {
+	return api.Introspect(c.Identify(ctx, token))
+}
+
+// accessToken is a Gitea access token. The sha1 value is
+// only returned when the token is created.
+type accessToken struct {
+	ID     int64    `json:"id,omitempty"`
+	Name   string   `json:"name"`
+	Sha1   string   `json:"sha1,omitempty"`
+	Scopes []string `json:"scopes,omitempty"`
+}
+
+// minter creates access tokens using the username and
+// password.
+type minter struct {
+	label  string
+	server string
+	scopes []string
+	client *http.Client
+	dumper logger.Dumper
+}
+
+// mint creates an access token and returns the token
+// value.
+func (m *minter) mint(ctx context.Context, user, pass, otp string) (string, error) {
+	token, err := m.createToken(ctx, user, pass, otp)
+	if err != nil {
+		return "", err
+	}
+	return token.Sha1, nil
+}
+
+// createToken creates an access token for the user. Gitea
+// token names are unique and the token value cannot be
+// retrieved after creation, so an existing token with the
+// same label is deleted and replaced. The one-time
+// password is required if the user account has two-factor
+// authentication enabled.
+func (m *minter) createToken(ctx context.Context, user, pass, otp string) (*accessToken, error) {
+	client := &api.Client{
+		Client: m.client,
+		Dumper: m.dumper,
+		Authorize: func(req *http.Request) error {
+			req.SetBasicAuth(user, pass)
+			if otp != "" {
+				req.Header.Set("X-Gitea-OTP", otp)
+			}
+			return nil
+		},
+	}
+	path := m.server + "/api/v1/users/" + url.PathEscape(user) + "/tokens"
+
+	// the token list is paginated, and the page size is
+	// capped by the server, so all pages are requested to
+	// find an existing token with the same label.
+	var tokens []*accessToken
+	for page := 1; ; page++ {
+		var list []*accessToken
+		query := fmt.Sprintf("?page=%d&limit=%d", page, pageSize)
+		if err := client.Get(ctx, path+query, &list); err != nil {
+			return nil, exchangeError(err)
+		}
+		tokens = append(tokens, list...)
+		if len(list) < pageSize {
+			break
+		}
+	}
+	for _, token := range tokens {
+		if token.Name != m.label {
+			continue
+		}
+		if err := client.Do(ctx, "DELETE", path+"/"+url.PathEscape(token.Name), nil, nil); err != nil {
+			return nil, exchangeError(err)
+		}
+	}
+
+	in := &accessToken{
+		Name:   m.label,
+		Scopes: m.scopes,
+	}
+	token := new(accessToken)
+	if err := client.Do(ctx, "POST", path, in, token); err != nil {
+		return nil, exchangeError(err)
+	}
+	return token, nil
+}
+
+// exchangeError converts the error returned when creating
+// the access token to a login error.
+func exchangeError(err error) error {
+	if e, ok := err.(*api.Error); ok {
+		switch {
+		case e.OTPRequired():
+			return &login.Error{Kind: login.ErrOTPRequired, Err: err}
+		case e.Status == http.StatusUnauthorized,
+			e.Status == http.StatusForbidden:
+			return &login.Error{Kind: login.ErrInvalidCredentials, Err: err}
+		}
+	}
+	return api.Wrap(login.ErrTokenExchange, err)
+}
@@ -0,0 +1,62 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/drone/go-login/login"
+	"github.com/drone/go-login/login/internal/api"
+)
+
+type user struct {
+	ID     int64  `json:"id"`
+	Login  string `json:"login"`
+	Name   string `json:"full_name"`
+	Email  string `json:"email"`
+	Avatar string `json:"avatar_url"`
+}
+
+// Identify returns the Gitea user that authorized the
+// token.
+func (c *Config) Identify(ctx context.Context, token *login.Token) (*login.User, error) {
+	if token == nil {
+		return nil, login.ErrToken
+	}
+	return identify(ctx, c.api(token), normalizeAddress(c.Server))
+}
+
+// Introspect returns the Gitea token details.
+func (c *Config) Introspect(ctx context.Context, token *login.Token) (*login.TokenInfo, error) {
+	return api.Introspect(c.Identify(ctx, token))
+}
+
+// identify returns the Gitea user using the authorized
+// api client.
+func identify(ctx context.Context, client *api.Client, server string) (*login.User, error) {
+	out := new(user)
+	err := client.Get(ctx, server+"/api/v1/user", out)
+	if err != nil {
+		return nil, api.Wrap(login.ErrIdentity, err)
+	}
+	return &login.User{
+		ID:     strconv.FormatInt(out.ID, 10),
+		Login:  out.Login,
+		Name:   out.Name,
+		Email:  out.Email,
+		Avatar: out.Avatar,
+		Host:   api.Host(server),
+	}, nil
+}
+
+// api returns an api client authorized with the token.
+func (c *Config) api(token *login.Token) *api.Client {
+	return &api.Client{
+		Client:    c.Client,
+		Dumper:    c.Dumper,
+		Authorize: api.Bearer(token.Access),
+	}
+}
@@ -0,0 +1,30 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"context"
+	"testing"
+
+	"github.com/drone/go-login/login"
+)
+
+func TestNilToken(t *testing.T) {
+	tests := []interface {
+		Identify(context.Context, *login.Token) (*login.User, error)
+		Introspect(context.Context, *login.Token) (*login.TokenInfo, error)
+	}{
+		&Config{},
+		&PasswordConfig{},
+	}
+	for _, c := range tests {
+		if _, err := c.Identify(context.Background(), nil); err != login.ErrToken {
+			t.Errorf("Want error %s, got %v", login.ErrToken, err)
+		}
+		if _, err := c.Introspect(context.Background(), nil); err != login.ErrToken {
+			t.Errorf("Want error %s, got %v", login.ErrToken, err)
+		}
+	}
+}
@@ -5,14 +5,22 @@
 package gitee
 
 import (
+	"context"
 	"net/http"
 	"strings"
 
 	"github.com/drone/go-login/login"
-	"github.com/drone/go-login/login/internal/oauth2"
+	"github.com/drone/go-login/login/logger"
+	"github.com/drone/go-login/login/oauth2"
 )
 
-var _ login.Middleware = (*Config)(nil)
+var (
+	_ login.Middleware   = (*Config)(nil)
+	_ login.Refresher    = (*Config)(nil)
+	_ login.Identifier   = (*Config)(nil)
+	_ login.Revoker      = (*Config)(nil)
+	_ login.Introspector = (*Config)(nil)
+)
 
 // Config configures the Gitee auth provider.
 type Config struct {
@@ -22,6 +30,11 @@ type Config struct {
 	Server       string
 	Scope        []string
 	Client       *http.Client
+	Logger       logger.Logger
+	Dumper       logger.Dumper
+	PKCE         bool
+	StateStore   login.StateStore
+	FetchUser    bool
 }
 
 // Handler returns a http.Handler that runs h at the
@@ -29,9 +42,25 @@ type Config struct {
 // authorization details are available to h in the
 // http.Request context.
 func (c *Config) Handler(h http.Handler) http.Handler {
+	return oauth2.Handler(h, c.config())
+}
+
+// Refresh refreshes the Gitee authorization token.
+func (c *Config) Refresh(ctx context.Context, token *login.Token) (*login.Token, error) {
+	return c.config().Refresh(ctx, token)
+}
+
+// Revoke returns ErrUnsupported. Gitee does not provide a
+// token revocation endpoint.
+func (c *Config) Revoke(ctx context.Context, token *login.Token) error {
+	return login.ErrUnsupported
+}
+
+// config returns the oauth2 configuration.
+func (c *Config) config() *oauth2.Config {
 	server := normalizeAddress(c.Server)
-	return oauth2.Handler(h, &oauth2.Config{
-		BasicAuthOff:     true,
+	conf := &oauth2.Config{
+		AuthStyle:        oauth2.AuthStyleParams,
 		Client:           c.Client,
 		ClientID:         c.ClientID,
 		ClientSecret:     c.ClientSecret,
@@ -39,7 +68,16 @@ func (c *Config) Handler(h http.Handler) http.Handler {
 		AccessTokenURL:   server + "/oauth/token",
 		AuthorizationURL: server + "/oauth/authorize",
 		Scope:            c.Scope,
-	})
+		Logger:           c.Logger,
+		Dumper:           c.Dumper,
+		PKCE:             c.PKCE,
+		StateStore:       c.StateStore,
+		Server:           server,
+	}
+	if c.FetchUser {
+		conf.Identifier = c
+	}
+	return conf
 }
 
 func normalizeAddress(address string) string {
@@ -47,4 +85,4 @@ func normalizeAddress(address string) string {
 		return "https://gitee.com"
 	}
 	return strings.TrimSuffix(address, "/")
-}
\ No newline at end of file
+}
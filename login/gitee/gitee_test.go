@@ -0,0 +1,18 @@
+// Copyright 2017 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gitee
+
+import "testing"
+
+func TestConfig(t *testing.T) {
+	conf := &Config{Server: "https://gitee.company.com/"}
+	c := conf.config()
+	if got, want := c.AuthorizationURL, "https://gitee.company.com/oauth/authorize"; got != want {
+		t.Errorf("Want authorization url %s, got %s", want, got)
+	}
+	if got, want := c.Server, "https://gitee.company.com"; got != want {
+		t.Errorf("Want server %s, got %s", want, got)
+	}
+}
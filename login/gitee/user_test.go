@@ -0,0 +1,101 @@
+// Copyright 2017 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gitee
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/drone/go-login/login"
+	"github.com/h2non/gock"
+)
+
+func TestIdentify(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://gitee.com").
+		Get("/api/v5/user").
+		MatchParam("access_token", "755bb80e5b").
+		Reply(200).
+		JSON(&user{
+			ID:     583231,
+			Login:  "janedoe",
+			Name:   "Jane Doe",
+			Email:  "janedoe@example.com",
+			Avatar: "https://gitee.com/assets/no_portrait.png",
+		})
+
+	gock.New("https://gitee.com").
+		Get("/api/v5/user").
+		Reply(401)
+
+	c := &Config{}
+	u, err := c.Identify(context.Background(), &login.Token{Access: "755bb80e5b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := login.User{
+		ID:     "583231",
+		Login:  "janedoe",
+		Name:   "Jane Doe",
+		Email:  "janedoe@example.com",
+		Avatar: "https://gitee.com/assets/no_portrait.png",
+		Host:   "gitee.com",
+	}
+	if *u != want {
+		t.Errorf("Want user %v, got %v", want, *u)
+	}
+
+	_, err = c.Identify(context.Background(), &login.Token{Access: "e08f3fa43e"})
+	if !errors.Is(err, login.ErrIdentity) {
+		t.Errorf("Want ErrIdentity, got %v", err)
+	}
+}
+
+func TestIntrospect(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://gitee.com").
+		Get("/api/v5/user").
+		MatchParam("access_token", "755bb80e5b").
+		Reply(200).
+		JSON(&user{ID: 583231, Login: "janedoe"})
+
+	gock.New("https://gitee.com").
+		Get("/api/v5/user").
+		Reply(401)
+
+	c := &Config{}
+	info, err := c.Introspect(context.Background(), &login.Token{Access: "755bb80e5b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.Active {
+		t.Errorf("Want active token")
+	}
+	if info.User == nil || info.User.Login != "janedoe" {
+		t.Errorf("Want token owned by janedoe")
+	}
+
+	// the token is revoked.
+	info, err = c.Introspect(context.Background(), &login.Token{Access: "e08f3fa43e"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Active {
+		t.Errorf("Want inactive token")
+	}
+}
+
+func TestNilToken(t *testing.T) {
+	c := &Config{}
+	if _, err := c.Identify(context.Background(), nil); err != login.ErrToken {
+		t.Errorf("Want error %s, got %v", login.ErrToken, err)
+	}
+	if _, err := c.Introspect(context.Background(), nil); err != login.ErrToken {
+		t.Errorf("Want error %s, got %v", login.ErrToken, err)
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright 2017 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/drone/go-login/login"
+	"github.com/drone/go-login/login/internal/api"
+)
+
+type membership struct {
+	State string `json:"state"`
+}
+
+// Authorize returns an error if the user that authorized
+// the token is not an active member of at least one of the
+// organizations. This requires the read:org scope.
+func (c *Config) Authorize(ctx context.Context, token *login.Token) error {
+	server := apiAddress(normalizeAddress(c.Server))
+	client := c.api(token)
+	for _, org := range c.Organizations {
+		out := new(membership)
+		err := client.Get(ctx, server+"/user/memberships/orgs/"+url.PathEscape(org), out)
+		if e, ok := err.(*api.Error); ok && e.Status == http.StatusNotFound {
+			continue
+		}
+		if err != nil {
+			return api.Wrap(login.ErrNotAuthorized, err)
+		}
+		if out.State == "active" {
+			return nil
+		}
+	}
+	return &login.Error{
+		Kind:        login.ErrNotAuthorized,
+		Description: "User is not a member of the required organizations",
+	}
+}
@@ -5,25 +5,54 @@
 package github
 
 import (
+	"context"
 	"net/http"
+	"net/url"
 	"strings"
 
 	"github.com/drone/go-login/login"
-	"github.com/drone/go-login/login/internal/oauth2"
+	"github.com/drone/go-login/login/internal/api"
 	"github.com/drone/go-login/login/logger"
+	"github.com/drone/go-login/login/oauth2"
 )
 
-var _ login.Middleware = (*Config)(nil)
+var (
+	_ login.Middleware       = (*Config)(nil)
+	_ login.Refresher        = (*Config)(nil)
+	_ login.Identifier       = (*Config)(nil)
+	_ login.Authorizer       = (*Config)(nil)
+	_ login.Revoker          = (*Config)(nil)
+	_ login.Introspector     = (*Config)(nil)
+	_ login.DeviceAuthorizer = (*Config)(nil)
+)
 
 // Config configures a GitHub authorization provider.
 type Config struct {
-	Client       *http.Client
-	ClientID     string
-	ClientSecret string
-	Server       string
-	Scope        []string
-	Logger       logger.Logger
-	Dumper       logger.Dumper
+	Client        *http.Client
+	ClientID      string
+	ClientSecret  string
+	Server        string
+	Scope         []string
+	Logger        logger.Logger
+	Dumper        logger.Dumper
+	PKCE          bool
+	StateStore    login.StateStore
+	FetchUser     bool
+	Organizations []string
+
+	// Login suggests the account used to sign in.
+	Login string
+
+	// DisableSignup hides the option to sign up for a
+	// GitHub account during the authorization flow.
+	DisableSignup bool
+
+	// AuthParams are additional authorization parameters.
+	AuthParams map[string]string
+
+	// RequestParams are the login request query parameters
+	// copied to the authorization request, such as login.
+	RequestParams []string
 }
 
 // Handler returns a http.Handler that runs h at the
@@ -31,18 +60,91 @@ type Config struct {
 // authorization details are available to h in the
 // http.Request context.
 func (c *Config) Handler(h http.Handler) http.Handler {
+	return oauth2.Handler(h, c.config())
+}
+
+// Refresh refreshes the GitHub authorization token.
+func (c *Config) Refresh(ctx context.Context, token *login.Token) (*login.Token, error) {
+	return c.config().Refresh(ctx, token)
+}
+
+// Revoke revokes the GitHub authorization grant, which
+// revokes all tokens issued to the application for the
+// user that authorized the token.
+func (c *Config) Revoke(ctx context.Context, token *login.Token) error {
+	if token == nil {
+		return login.ErrToken
+	}
+	client := &api.Client{
+		Client:    c.Client,
+		Dumper:    c.Dumper,
+		Authorize: api.Basic(c.ClientID, c.ClientSecret),
+	}
+	in := map[string]string{"access_token": token.Access}
+	path := apiAddress(normalizeAddress(c.Server)) + "/applications/" + url.PathEscape(c.ClientID) + "/grant"
+	if err := client.Do(ctx, "DELETE", path, in, nil); err != nil {
+		return api.Wrap(login.ErrRevocation, err)
+	}
+	return nil
+}
+
+// DeviceCode requests a GitHub device code, used to
+// authorize command line clients that cannot receive the
+// authorization callback.
+func (c *Config) DeviceCode(ctx context.Context) (*login.DeviceCode, error) {
+	return c.config().DeviceCode(ctx)
+}
+
+// DeviceToken polls GitHub until the user authorizes the
+// device code, and returns the authorization token.
+func (c *Config) DeviceToken(ctx context.Context, code *login.DeviceCode) (*login.Token, error) {
+	return c.config().DeviceToken(ctx, code)
+}
+
+// config returns the oauth2 configuration.
+func (c *Config) config() *oauth2.Config {
 	server := normalizeAddress(c.Server)
-	return oauth2.Handler(h, &oauth2.Config{
-		BasicAuthOff:     true,
+	conf := &oauth2.Config{
+		AuthStyle:        oauth2.AuthStyleParams,
 		Client:           c.Client,
 		ClientID:         c.ClientID,
 		ClientSecret:     c.ClientSecret,
 		AccessTokenURL:   server + "/login/oauth/access_token",
 		AuthorizationURL: server + "/login/oauth/authorize",
+		DeviceAuthURL:    server + "/login/device/code",
 		Scope:            c.Scope,
+		LoginHintParam:   "login",
 		Logger:           c.Logger,
 		Dumper:           c.Dumper,
-	})
+		PKCE:             c.PKCE,
+		StateStore:       c.StateStore,
+		Server:           server,
+		AuthParams:       c.authParams(),
+		RequestParams:    c.RequestParams,
+	}
+	if c.FetchUser {
+		conf.Identifier = c
+	}
+	if len(c.Organizations) != 0 {
+		conf.Authorizer = c
+	}
+	return conf
+}
+
+// authParams returns the additional authorization
+// parameters.
+func (c *Config) authParams() map[string]string {
+	params := map[string]string{}
+	for key, value := range c.AuthParams {
+		params[key] = value
+	}
+	if len(c.Login) != 0 {
+		params["login"] = c.Login
+	}
+	if c.DisableSignup {
+		params["allow_signup"] = "false"
+	}
+	return params
 }
 
 func normalizeAddress(address string) string {
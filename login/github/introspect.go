@@ -0,0 +1,65 @@
+// Copyright 2017 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/drone/go-login/login"
+	"github.com/drone/go-login/login/internal/api"
+)
+
+type authorization struct {
+	Scopes  []string   `json:"scopes"`
+	Expires *time.Time `json:"expires_at"`
+	User    *user      `json:"user"`
+}
+
+// Introspect returns the GitHub token details using the
+// check token endpoint, which is authorized with the
+// application client credentials.
+func (c *Config) Introspect(ctx context.Context, token *login.Token) (*login.TokenInfo, error) {
+	if token == nil {
+		return nil, login.ErrToken
+	}
+	server := normalizeAddress(c.Server)
+	client := &api.Client{
+		Client:    c.Client,
+		Dumper:    c.Dumper,
+		Authorize: api.Basic(c.ClientID, c.ClientSecret),
+	}
+	in := map[string]string{"access_token": token.Access}
+	out := new(authorization)
+	path := apiAddress(server) + "/applications/" + url.PathEscape(c.ClientID) + "/token"
+	err := client.Do(ctx, "POST", path, in, out)
+	if e, ok := err.(*api.Error); ok && e.Status == http.StatusNotFound {
+		return &login.TokenInfo{Active: false}, nil
+	}
+	if err != nil {
+		return nil, api.Wrap(login.ErrIntrospection, err)
+	}
+	info := &login.TokenInfo{
+		Active: true,
+		Scopes: out.Scopes,
+	}
+	if out.Expires != nil {
+		info.Expires = *out.Expires
+	}
+	if out.User != nil {
+		info.User = &login.User{
+			ID:     strconv.FormatInt(out.User.ID, 10),
+			Login:  out.User.Login,
+			Name:   out.User.Name,
+			Email:  out.User.Email,
+			Avatar: out.User.Avatar,
+			Host:   api.Host(server),
+		}
+	}
+	return info, nil
+}
@@ -0,0 +1,81 @@
+// Copyright 2017 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package github
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/drone/go-login/login"
+	"github.com/drone/go-login/login/internal/api"
+)
+
+type user struct {
+	ID     int64  `json:"id"`
+	Login  string `json:"login"`
+	Name   string `json:"name"`
+	Email  string `json:"email"`
+	Avatar string `json:"avatar_url"`
+}
+
+type email struct {
+	Email    string `json:"email"`
+	Primary  bool   `json:"primary"`
+	Verified bool   `json:"verified"`
+}
+
+// Identify returns the GitHub user that authorized the
+// token.
+func (c *Config) Identify(ctx context.Context, token *login.Token) (*login.User, error) {
+	if token == nil {
+		return nil, login.ErrToken
+	}
+	server := normalizeAddress(c.Server)
+	client := c.api(token)
+	out := new(user)
+	err := client.Get(ctx, apiAddress(server)+"/user", out)
+	if err != nil {
+		return nil, api.Wrap(login.ErrIdentity, err)
+	}
+	// the public email address is empty if the user keeps
+	// their email address private, in which case the primary
+	// verified email address is used. This requires the
+	// user:email scope and is skipped on error.
+	if out.Email == "" {
+		var emails []*email
+		client.Get(ctx, apiAddress(server)+"/user/emails", &emails)
+		for _, e := range emails {
+			if e.Primary && e.Verified {
+				out.Email = e.Email
+			}
+		}
+	}
+	return &login.User{
+		ID:     strconv.FormatInt(out.ID, 10),
+		Login:  out.Login,
+		Name:   out.Name,
+		Email:  out.Email,
+		Avatar: out.Avatar,
+		Host:   api.Host(server),
+	}, nil
+}
+
+// api returns an api client authorized with the token.
+func (c *Config) api(token *login.Token) *api.Client {
+	return &api.Client{
+		Client:    c.Client,
+		Dumper:    c.Dumper,
+		Authorize: api.Bearer(token.Access),
+	}
+}
+
+// apiAddress returns the GitHub api address for the
+// server address.
+func apiAddress(server string) string {
+	if server == "https://github.com" {
+		return "https://api.github.com"
+	}
+	return server + "/api/v3"
+}
@@ -0,0 +1,63 @@
+// Copyright 2017 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package github
+
+import (
+	"context"
+	"testing"
+
+	"github.com/drone/go-login/login"
+	"github.com/h2non/gock"
+)
+
+func TestIdentify(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://api.github.com").
+		Get("/user").
+		MatchHeader("Authorization", "Bearer 755bb80e5b").
+		Reply(200).
+		JSON(&user{
+			ID:     583231,
+			Login:  "octocat",
+			Name:   "The Octocat",
+			Avatar: "https://avatars.githubusercontent.com/u/583231",
+		})
+
+	gock.New("https://api.github.com").
+		Get("/user/emails").
+		Reply(200).
+		JSON([]*email{
+			{Email: "octocat@users.noreply.github.com", Verified: true},
+			{Email: "octocat@github.com", Primary: true, Verified: true},
+		})
+
+	c := &Config{}
+	u, err := c.Identify(context.Background(), &login.Token{Access: "755bb80e5b"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	want := login.User{
+		ID:     "583231",
+		Login:  "octocat",
+		Name:   "The Octocat",
+		Email:  "octocat@github.com",
+		Avatar: "https://avatars.githubusercontent.com/u/583231",
+		Host:   "github.com",
+	}
+	if *u != want {
+		t.Errorf("Want user %v, got %v", want, *u)
+	}
+}
+
+func TestAPIAddress(t *testing.T) {
+	if got, want := apiAddress("https://github.com"), "https://api.github.com"; got != want {
+		t.Errorf("Want api address %s, got %s", want, got)
+	}
+	if got, want := apiAddress("https://github.company.com"), "https://github.company.com/api/v3"; got != want {
+		t.Errorf("Want api address %s, got %s", want, got)
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright 2017 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/drone/go-login/login"
+	"github.com/drone/go-login/login/internal/api"
+)
+
+// Authorize returns an error if the user that authorized
+// the token is not a direct or inherited member of at least
+// one of the groups. Groups are identified by full path.
+func (c *Config) Authorize(ctx context.Context, token *login.Token) error {
+	server := normalizeAddress(c.Server)
+	client := c.api(token)
+	u := new(user)
+	err := client.Get(ctx, server+"/api/v4/user", u)
+	if err != nil {
+		return api.Wrap(login.ErrNotAuthorized, err)
+	}
+	for _, group := range c.Groups {
+		path := fmt.Sprintf("%s/api/v4/groups/%s/members/all/%d", server, url.PathEscape(group), u.ID)
+		err := client.Get(ctx, path, nil)
+		if e, ok := err.(*api.Error); ok && e.Status == http.StatusNotFound {
+			continue
+		}
+		if err != nil {
+			return api.Wrap(login.ErrNotAuthorized, err)
+		}
+		return nil
+	}
+	return &login.Error{
+		Kind:        login.ErrNotAuthorized,
+		Description: "User is not a member of the required groups",
+	}
+}
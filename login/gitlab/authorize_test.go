@@ -0,0 +1,67 @@
+// Copyright 2017 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/drone/go-login/login"
+	"github.com/drone/go-login/login/internal/api"
+)
+
+func TestAuthorize(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/user", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(&user{ID: 42, Username: "janedoe"})
+	})
+	mux.HandleFunc("/api/v4/groups/", func(w http.ResponseWriter, r *http.Request) {
+		// subgroups are identified by the url-encoded full
+		// path of the group.
+		switch r.URL.EscapedPath() {
+		case "/api/v4/groups/drone%2Fcore/members/all/42":
+		case "/api/v4/groups/drone%2Fops/members/all/42":
+			w.WriteHeader(403)
+			return
+		default:
+			w.WriteHeader(404)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": 42, "access_level": 30})
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	tests := []struct {
+		groups []string
+		err    error
+		status int
+	}{
+		{groups: []string{"drone/core"}},
+		{groups: []string{"drone", "drone/core"}},
+		{groups: []string{"drone"}, err: login.ErrNotAuthorized},
+		// the membership check fails for reasons other than
+		// non-membership, and the cause is returned.
+		{groups: []string{"drone/ops", "drone/core"}, err: login.ErrNotAuthorized, status: 403},
+	}
+	for _, test := range tests {
+		c := &Config{Server: ts.URL, Groups: test.groups}
+		err := c.Authorize(context.Background(), &login.Token{Access: "755bb80e5b"})
+		if test.err == nil && err != nil {
+			t.Errorf("Want no error for %v, got %v", test.groups, err)
+		}
+		if test.err != nil && !errors.Is(err, test.err) {
+			t.Errorf("Want error %v for %v, got %v", test.err, test.groups, err)
+		}
+		var e *api.Error
+		if test.status != 0 && (!errors.As(err, &e) || e.Status != test.status) {
+			t.Errorf("Want status %d cause for %v, got %v", test.status, test.groups, err)
+		}
+	}
+}
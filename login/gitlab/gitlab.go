@@ -5,14 +5,24 @@
 package gitlab
 
 import (
+	"context"
 	"net/http"
 	"strings"
 
 	"github.com/drone/go-login/login"
-	"github.com/drone/go-login/login/internal/oauth2"
+	"github.com/drone/go-login/login/logger"
+	"github.com/drone/go-login/login/oauth2"
 )
 
-var _ login.Middleware = (*Config)(nil)
+var (
+	_ login.Middleware       = (*Config)(nil)
+	_ login.Refresher        = (*Config)(nil)
+	_ login.Identifier       = (*Config)(nil)
+	_ login.Revoker          = (*Config)(nil)
+	_ login.Introspector     = (*Config)(nil)
+	_ login.DeviceAuthorizer = (*Config)(nil)
+	_ login.Authorizer       = (*Config)(nil)
+)
 
 // Config configures the GitLab auth provider.
 type Config struct {
@@ -22,6 +32,27 @@ type Config struct {
 	Server       string
 	Scope        []string
 	Client       *http.Client
+	Logger       logger.Logger
+	Dumper       logger.Dumper
+	PKCE         bool
+	StateStore   login.StateStore
+	FetchUser    bool
+	Groups       []string
+
+	// Prompt is the prompt parameter, such as consent or
+	// login, sent with the authorization request.
+	Prompt string
+
+	// LoginHint suggests the account used to sign in.
+	LoginHint string
+
+	// AuthParams are additional authorization parameters.
+	AuthParams map[string]string
+
+	// RequestParams are the login request query parameters
+	// copied to the authorization request, such as
+	// login_hint.
+	RequestParams []string
 }
 
 // Handler returns a http.Handler that runs h at the
@@ -29,17 +60,77 @@ type Config struct {
 // authorization details are available to h in the
 // http.Request context.
 func (c *Config) Handler(h http.Handler) http.Handler {
+	return oauth2.Handler(h, c.config())
+}
+
+// Refresh refreshes the GitLab authorization token.
+func (c *Config) Refresh(ctx context.Context, token *login.Token) (*login.Token, error) {
+	return c.config().Refresh(ctx, token)
+}
+
+// Revoke revokes the GitLab authorization token.
+func (c *Config) Revoke(ctx context.Context, token *login.Token) error {
+	return c.config().Revoke(ctx, token)
+}
+
+// DeviceCode requests a GitLab device code, used to
+// authorize command line clients that cannot receive the
+// authorization callback.
+func (c *Config) DeviceCode(ctx context.Context) (*login.DeviceCode, error) {
+	return c.config().DeviceCode(ctx)
+}
+
+// DeviceToken polls GitLab until the user authorizes the
+// device code, and returns the authorization token.
+func (c *Config) DeviceToken(ctx context.Context, code *login.DeviceCode) (*login.Token, error) {
+	return c.config().DeviceToken(ctx, code)
+}
+
+// config returns the oauth2 configuration.
+func (c *Config) config() *oauth2.Config {
 	server := normalizeAddress(c.Server)
-	return oauth2.Handler(h, &oauth2.Config{
-		BasicAuthOff:     true,
+	conf := &oauth2.Config{
+		AuthStyle:        oauth2.AuthStyleParams,
 		Client:           c.Client,
 		ClientID:         c.ClientID,
 		ClientSecret:     c.ClientSecret,
 		RedirectURL:      c.RedirectURL,
 		AccessTokenURL:   server + "/oauth/token",
 		AuthorizationURL: server + "/oauth/authorize",
+		RevocationURL:    server + "/oauth/revoke",
+		DeviceAuthURL:    server + "/oauth/authorize_device",
 		Scope:            c.Scope,
-	})
+		Logger:           c.Logger,
+		Dumper:           c.Dumper,
+		PKCE:             c.PKCE,
+		StateStore:       c.StateStore,
+		Server:           server,
+		AuthParams:       c.authParams(),
+		RequestParams:    c.RequestParams,
+	}
+	if c.FetchUser {
+		conf.Identifier = c
+	}
+	if len(c.Groups) != 0 {
+		conf.Authorizer = c
+	}
+	return conf
+}
+
+// authParams returns the additional authorization
+// parameters.
+func (c *Config) authParams() map[string]string {
+	params := map[string]string{}
+	for key, value := range c.AuthParams {
+		params[key] = value
+	}
+	if len(c.Prompt) != 0 {
+		params["prompt"] = c.Prompt
+	}
+	if len(c.LoginHint) != 0 {
+		params["login_hint"] = c.LoginHint
+	}
+	return params
 }
 
 func normalizeAddress(address string) string {
@@ -0,0 +1,52 @@
+// Copyright 2017 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gitlab
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/drone/go-login/login"
+	"github.com/drone/go-login/login/internal/api"
+)
+
+type tokenInfo struct {
+	Owner     int64    `json:"resource_owner_id"`
+	Scope     []string `json:"scope"`
+	ExpiresIn *int64   `json:"expires_in"`
+}
+
+// Introspect returns the GitLab token details using the
+// token info endpoint.
+func (c *Config) Introspect(ctx context.Context, token *login.Token) (*login.TokenInfo, error) {
+	if token == nil {
+		return nil, login.ErrToken
+	}
+	server := normalizeAddress(c.Server)
+	out := new(tokenInfo)
+	err := c.api(token).Get(ctx, server+"/oauth/token/info", out)
+	if e, ok := err.(*api.Error); ok && e.Status == http.StatusUnauthorized {
+		return &login.TokenInfo{Active: false}, nil
+	}
+	if err != nil {
+		return nil, api.Wrap(login.ErrIntrospection, err)
+	}
+	info := &login.TokenInfo{
+		Active: true,
+		Scopes: out.Scope,
+		User: &login.User{
+			ID:   strconv.FormatInt(out.Owner, 10),
+			Host: api.Host(server),
+		},
+	}
+	if out.ExpiresIn != nil {
+		info.Expires = time.Now().UTC().Add(
+			time.Duration(*out.ExpiresIn) * time.Second,
+		)
+	}
+	return info, nil
+}
@@ -0,0 +1,65 @@
+// Copyright 2017 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/drone/go-login/login"
+)
+
+func TestIntrospect(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth/token/info", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer 755bb80e5b" {
+			w.WriteHeader(401)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"resource_owner_id": 42,
+			"scope":             []string{"api", "read_user"},
+			"expires_in":        7200,
+		})
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	c := &Config{Server: ts.URL}
+	info, err := c.Introspect(context.Background(), &login.Token{Access: "755bb80e5b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.Active {
+		t.Errorf("Want active token")
+	}
+	if got, want := info.User.ID, "42"; got != want {
+		t.Errorf("Want user id %s, got %s", want, got)
+	}
+	if info.Expires.IsZero() {
+		t.Errorf("Want token expiry")
+	}
+
+	info, err = c.Introspect(context.Background(), &login.Token{Access: "e08f3fa43e"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Active {
+		t.Errorf("Want inactive token")
+	}
+}
+
+func TestNilToken(t *testing.T) {
+	c := &Config{}
+	if _, err := c.Identify(context.Background(), nil); err != login.ErrToken {
+		t.Errorf("Want error %s, got %v", login.ErrToken, err)
+	}
+	if _, err := c.Introspect(context.Background(), nil); err != login.ErrToken {
+		t.Errorf("Want error %s, got %v", login.ErrToken, err)
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2017 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gitlab
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/drone/go-login/login"
+	"github.com/drone/go-login/login/internal/api"
+)
+
+type user struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Avatar   string `json:"avatar_url"`
+}
+
+// Identify returns the GitLab user that authorized the
+// token.
+func (c *Config) Identify(ctx context.Context, token *login.Token) (*login.User, error) {
+	if token == nil {
+		return nil, login.ErrToken
+	}
+	server := normalizeAddress(c.Server)
+	out := new(user)
+	err := c.api(token).Get(ctx, server+"/api/v4/user", out)
+	if err != nil {
+		return nil, api.Wrap(login.ErrIdentity, err)
+	}
+	return &login.User{
+		ID:     strconv.FormatInt(out.ID, 10),
+		Login:  out.Username,
+		Name:   out.Name,
+		Email:  out.Email,
+		Avatar: out.Avatar,
+		Host:   api.Host(server),
+	}, nil
+}
+
+// api returns an api client authorized with the token.
+func (c *Config) api(token *login.Token) *api.Client {
+	return &api.Client{
+		Client:    c.Client,
+		Dumper:    c.Dumper,
+		Authorize: api.Bearer(token.Access),
+	}
+}
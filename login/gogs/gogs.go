@@ -5,20 +5,31 @@
 package gogs
 
 import (
+	"context"
 	"net/http"
 	"strings"
 
 	"github.com/drone/go-login/login"
+	"github.com/drone/go-login/login/internal/password"
+	"github.com/drone/go-login/login/logger"
 )
 
-var _ login.Middleware = (*Config)(nil)
+var (
+	_ login.Middleware   = (*Config)(nil)
+	_ login.Identifier   = (*Config)(nil)
+	_ login.Revoker      = (*Config)(nil)
+	_ login.Introspector = (*Config)(nil)
+)
 
 // Config configures the Gogs auth provider.
 type Config struct {
-	Label  string
-	Login  string
-	Server string
-	Client *http.Client
+	Label     string
+	Login     string
+	Server    string
+	Client    *http.Client
+	Logger    logger.Logger
+	Dumper    logger.Dumper
+	FetchUser bool
 }
 
 // Handler returns a http.Handler that runs h at the
@@ -26,12 +37,26 @@ type Config struct {
 // authorization details are available to h in the
 // http.Request context.
 func (c *Config) Handler(h http.Handler) http.Handler {
-	v := &handler{
-		next:   h,
+	v := &password.Handler{
+		Next:   h,
+		Name:   "gogs",
+		Login:  c.Login,
+		Mint:   c.minter().mint,
+		Logger: c.Logger,
+	}
+	if c.FetchUser {
+		v.Identifier = c
+	}
+	return v
+}
+
+// minter returns the access token minter.
+func (c *Config) minter() *minter {
+	v := &minter{
 		label:  c.Label,
-		login:  c.Login,
 		server: strings.TrimSuffix(c.Server, "/"),
 		client: c.Client,
+		dumper: c.Dumper,
 	}
 	if v.client == nil {
 		v.client = http.DefaultClient
@@ -41,3 +66,11 @@ func (c *Config) Handler(h http.Handler) http.Handler {
 	}
 	return v
 }
+
+// Revoke returns ErrUnsupported. The Gogs api can list
+// and create access tokens, but has no endpoint to delete
+// them, so tokens can only be deleted by the user in the
+// Gogs settings.
+func (c *Config) Revoke(ctx context.Context, token *login.Token) error {
+	return login.ErrUnsupported
+}
@@ -5,8 +5,12 @@
 package gogs
 
 import (
+	"context"
 	"net/http"
 	"testing"
+
+	"github.com/drone/go-login/login"
+	"github.com/drone/go-login/login/internal/password"
 )
 
 func TestAuthorizer(t *testing.T) {
@@ -18,22 +22,23 @@ func TestAuthorizer(t *testing.T) {
 		Server: "https://try.gogs.io/",
 		Client: c,
 	}
-	v := a.Handler(h).(*handler)
-	if got, want := v.login, "/path/to/login"; got != want {
+	v := a.Handler(h).(*password.Handler)
+	if got, want := v.Login, "/path/to/login"; got != want {
 		t.Errorf("Expect login redirect url %q, got %q", want, got)
 	}
-	if got, want := v.server, "https://try.gogs.io"; got != want {
+	if got, want := v.Next, h; got != want {
+		t.Errorf("Expect handler wrapped")
+	}
+	m := a.minter()
+	if got, want := m.server, "https://try.gogs.io"; got != want {
 		t.Errorf("Expect server address %q, got %q", want, got)
 	}
-	if got, want := v.label, "drone"; got != want {
+	if got, want := m.label, "drone"; got != want {
 		t.Errorf("Expect label %q, got %q", want, got)
 	}
-	if got, want := v.client, c; got != want {
+	if got, want := m.client, c; got != want {
 		t.Errorf("Expect custom client")
 	}
-	if got, want := v.next, h; got != want {
-		t.Errorf("Expect handler wrapped")
-	}
 }
 
 func TestAuthorizerDefault(t *testing.T) {
@@ -41,9 +46,7 @@ func TestAuthorizerDefault(t *testing.T) {
 		Login:  "/path/to/login",
 		Server: "https://try.gogs.io",
 	}
-	v := a.Handler(
-		http.NotFoundHandler(),
-	).(*handler)
+	v := a.minter()
 	if got, want := v.label, "default"; got != want {
 		t.Errorf("Expect label %q, got %q", want, got)
 	}
@@ -51,3 +54,13 @@ func TestAuthorizerDefault(t *testing.T) {
 		t.Errorf("Expect custom client")
 	}
 }
+
+func TestNilToken(t *testing.T) {
+	c := &Config{}
+	if _, err := c.Identify(context.Background(), nil); err != login.ErrToken {
+		t.Errorf("Want error %s, got %v", login.ErrToken, err)
+	}
+	if _, err := c.Introspect(context.Background(), nil); err != login.ErrToken {
+		t.Errorf("Want error %s, got %v", login.ErrToken, err)
+	}
+}
@@ -6,12 +6,14 @@ package gogs
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"net/http"
 
 	"github.com/drone/go-login/login"
+	"github.com/drone/go-login/login/internal/api"
+	"github.com/drone/go-login/login/logger"
 )
 
 type token struct {
@@ -19,35 +21,27 @@ type token struct {
 	Sha1 string `json:"sha1,omitempty"`
 }
 
-type handler struct {
-	next   http.Handler
+// minter creates access tokens using the username and
+// password.
+type minter struct {
 	label  string
-	login  string
 	server string
 	client *http.Client
+	dumper logger.Dumper
 }
 
-func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-	user := r.FormValue("username")
-	pass := r.FormValue("password")
-	if (user == "" || pass == "") && h.login != "" {
-		http.Redirect(w, r, h.login, 303)
-		return
-	}
-	token, err := h.createFindToken(user, pass)
+// mint returns the access token with the label, creating
+// the token if it does not exist.
+func (h *minter) mint(ctx context.Context, user, pass, otp string) (string, error) {
+	token, err := h.createFindToken(ctx, user, pass, otp)
 	if err != nil {
-		ctx = login.WithError(ctx, err)
-	} else {
-		ctx = login.WithToken(ctx, &login.Token{
-			Access: token.Sha1,
-		})
+		return "", err
 	}
-	h.next.ServeHTTP(w, r.WithContext(ctx))
+	return token.Sha1, nil
 }
 
-func (h *handler) createFindToken(user, pass string) (*token, error) {
-	tokens, err := h.findTokens(user, pass)
+func (h *minter) createFindToken(ctx context.Context, user, pass, otp string) (*token, error) {
+	tokens, err := h.findTokens(ctx, user, pass, otp)
 	if err != nil {
 		return nil, err
 	}
@@ -56,10 +50,10 @@ func (h *handler) createFindToken(user, pass string) (*token, error) {
 			return token, nil
 		}
 	}
-	return h.createToken(user, pass)
+	return h.createToken(ctx, user, pass, otp)
 }
 
-func (h *handler) createToken(user, pass string) (*token, error) {
+func (h *minter) createToken(ctx context.Context, user, pass, otp string) (*token, error) {
 	path := fmt.Sprintf("%s/api/v1/users/%s/tokens", h.server, user)
 
 	buf := new(bytes.Buffer)
@@ -71,18 +65,20 @@ func (h *handler) createToken(user, pass string) (*token, error) {
 	if err != nil {
 		return nil, err
 	}
+	req = req.WithContext(ctx)
 	req.Header.Set("Content-Type", "application/json")
 	req.SetBasicAuth(user, pass)
+	if otp != "" {
+		req.Header.Set("X-Gogs-OTP", otp)
+	}
 
-	res, err := h.client.Do(req)
+	res, err := h.do(req)
 	if err != nil {
-		return nil, err
+		return nil, &login.Error{Kind: login.ErrUnreachable, Err: err}
 	}
 	defer res.Body.Close()
 	if res.StatusCode > 299 {
-		return nil, errors.New(
-			http.StatusText(res.StatusCode),
-		)
+		return nil, responseError(res)
 	}
 
 	out := new(token)
@@ -90,27 +86,63 @@ func (h *handler) createToken(user, pass string) (*token, error) {
 	return out, err
 }
 
-func (h *handler) findTokens(user, pass string) ([]*token, error) {
+func (h *minter) findTokens(ctx context.Context, user, pass, otp string) ([]*token, error) {
 	path := fmt.Sprintf("%s/api/v1/users/%s/tokens", h.server, user)
 	req, err := http.NewRequest("GET", path, nil)
 	if err != nil {
 		return nil, err
 	}
+	req = req.WithContext(ctx)
 	req.Header.Set("Content-Type", "application/json")
 	req.SetBasicAuth(user, pass)
+	if otp != "" {
+		req.Header.Set("X-Gogs-OTP", otp)
+	}
 
-	res, err := h.client.Do(req)
+	res, err := h.do(req)
 	if err != nil {
-		return nil, err
+		return nil, &login.Error{Kind: login.ErrUnreachable, Err: err}
 	}
 	defer res.Body.Close()
 	if res.StatusCode > 299 {
-		return nil, errors.New(
-			http.StatusText(res.StatusCode),
-		)
+		return nil, responseError(res)
 	}
 
 	out := []*token{}
 	err = json.NewDecoder(res.Body).Decode(&out)
 	return out, err
 }
+
+// do sends the http.Request and dumps the request and
+// response if a dumper is configured.
+func (h *minter) do(req *http.Request) (*http.Response, error) {
+	if h.dumper != nil {
+		h.dumper.DumpRequest(req)
+	}
+	res, err := h.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if h.dumper != nil {
+		h.dumper.DumpResponse(res)
+	}
+	return res, nil
+}
+
+// responseError returns a login error for the failed
+// http.Response.
+func responseError(res *http.Response) error {
+	cause := api.NewError(res)
+	err := &login.Error{
+		Kind: login.ErrTokenExchange,
+		Err:  cause,
+	}
+	switch {
+	case cause.OTPRequired():
+		err.Kind = login.ErrOTPRequired
+	case res.StatusCode == http.StatusUnauthorized,
+		res.StatusCode == http.StatusForbidden:
+		err.Kind = login.ErrInvalidCredentials
+	}
+	return err
+}
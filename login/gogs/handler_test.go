@@ -55,7 +55,7 @@ func TestLogin(t *testing.T) {
 			auth:   "Basic amFuZWRvZTpwYXNzd29yZA==",
 			tokens: nil,
 			token:  nil,
-			err:    errors.New("Not Found"),
+			err:    login.ErrTokenExchange,
 		},
 		// Failure, match not found, error creating token.
 		{
@@ -65,7 +65,7 @@ func TestLogin(t *testing.T) {
 			auth:   "Basic amFuZWRvZTpwYXNzd29yZA==",
 			tokens: []*token{{Name: "some-random-token-name", Sha1: "918a808c2"}},
 			token:  nil,
-			err:    errors.New("Not Found"),
+			err:    login.ErrTokenExchange,
 		},
 	}
 
@@ -128,8 +128,10 @@ func TestLogin(t *testing.T) {
 		if test.err != nil {
 			if err == nil {
 				t.Errorf("Want error")
-			} else if got, want := err.Error(), test.err.Error(); got != want {
-				t.Errorf("Want error %q, got %q", want, got)
+			} else if !errors.Is(err, test.err) {
+				t.Errorf("Want error %q, got %q", test.err, err)
+			} else if got, want := errors.Unwrap(err).Error(), "Not Found"; got != want {
+				t.Errorf("Want error cause %q, got %q", want, got)
 			}
 		} else {
 			if tok == nil {
@@ -141,6 +143,88 @@ func TestLogin(t *testing.T) {
 	}
 }
 
+func TestLoginUnauthorized(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://gogs.io").
+		Get("/api/v1/users/janedoe/token").
+		Reply(401)
+
+	var ctx context.Context
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		ctx = r.Context()
+	}
+	v := &Config{
+		Server: "https://try.gogs.io",
+		Login:  "/login/form",
+	}
+	h := v.Handler(http.HandlerFunc(fn))
+
+	data := url.Values{
+		"username": {"janedoe"},
+		"password": {"password"},
+	}.Encode()
+	req := httptest.NewRequest("POST", "/", strings.NewReader(data))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	if err := login.ErrorFrom(ctx); !errors.Is(err, login.ErrInvalidCredentials) {
+		t.Errorf("Want error %v, got %v", login.ErrInvalidCredentials, err)
+	}
+}
+
+func TestLoginOTP(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://gogs.io").
+		Get("/api/v1/users/janedoe/token").
+		MatchHeader("X-Gogs-OTP", "123456").
+		Reply(200).
+		JSON([]*token{{Name: "default", Sha1: "3da541559"}})
+
+	gock.New("https://gogs.io").
+		Get("/api/v1/users/janedoe/token").
+		Reply(401).
+		SetHeader("X-Gogs-OTP", "required")
+
+	var ctx context.Context
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		ctx = r.Context()
+	}
+	v := &Config{
+		Server: "https://try.gogs.io",
+		Login:  "/login/form",
+	}
+	h := v.Handler(http.HandlerFunc(fn))
+
+	// the first submit does not include the one-time
+	// password.
+	data := url.Values{
+		"username": {"janedoe"},
+		"password": {"password"},
+	}
+	req := httptest.NewRequest("POST", "/", strings.NewReader(data.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	if err := login.ErrorFrom(ctx); !errors.Is(err, login.ErrOTPRequired) {
+		t.Errorf("Want error %v, got %v", login.ErrOTPRequired, err)
+	}
+
+	// the second submit includes the one-time password.
+	data.Set("otp", "123456")
+	req = httptest.NewRequest("POST", "/", strings.NewReader(data.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	if err := login.ErrorFrom(ctx); err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if tok := login.TokenFrom(ctx); tok == nil || tok.Access != "3da541559" {
+		t.Errorf("Want access token 3da541559")
+	}
+}
+
 func TestLoginRedirect(t *testing.T) {
 	v := &Config{
 		Server: "https://try.gogs.io",
@@ -161,3 +245,25 @@ func TestLoginRedirect(t *testing.T) {
 		t.Errorf("Want redirect location %s, got %s", want, got)
 	}
 }
+
+// TestLoginCanceled uses a local server because gock does
+// not honor the request context.
+func TestLoginCanceled(t *testing.T) {
+	var called bool
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	a := &Config{Server: ts.URL}
+	_, err := a.minter().mint(ctx, "janedoe", "password", "")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Want context canceled, got %v", err)
+	}
+	if called {
+		t.Errorf("Expect no request to the server")
+	}
+}
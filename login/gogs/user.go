@@ -0,0 +1,58 @@
+// Copyright 2017 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gogs
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/drone/go-login/login"
+	"github.com/drone/go-login/login/internal/api"
+)
+
+type user struct {
+	ID       int64  `json:"id"`
+	Login    string `json:"login"`
+	Username string `json:"username"`
+	Name     string `json:"full_name"`
+	Email    string `json:"email"`
+	Avatar   string `json:"avatar_url"`
+}
+
+// Identify returns the Gogs user that owns the token.
+func (c *Config) Identify(ctx context.Context, token *login.Token) (*login.User, error) {
+	if token == nil {
+		return nil, login.ErrToken
+	}
+	server := strings.TrimSuffix(c.Server, "/")
+	client := &api.Client{
+		Client:    c.Client,
+		Dumper:    c.Dumper,
+		Authorize: api.Token(token.Access),
+	}
+	out := new(user)
+	err := client.Get(ctx, server+"/api/v1/user", out)
+	if err != nil {
+		return nil, api.Wrap(login.ErrIdentity, err)
+	}
+	username := out.Login
+	if username == "" {
+		username = out.Username
+	}
+	return &login.User{
+		ID:     strconv.FormatInt(out.ID, 10),
+		Login:  username,
+		Name:   out.Name,
+		Email:  out.Email,
+		Avatar: out.Avatar,
+		Host:   api.Host(server),
+	}, nil
+}
+
+// Introspect returns the Gogs token details.
+func (c *Config) Introspect(ctx context.Context, token *login.Token) (*login.TokenInfo, error) {
+	return api.Introspect(c.Identify(ctx, token))
+}
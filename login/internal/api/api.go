@@ -0,0 +1,236 @@
+// Copyright 2017 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package api provides a minimal client for the provider
+// rest apis used to complete the login flow.
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/drone/go-login/login"
+	"github.com/drone/go-login/login/logger"
+)
+
+// Client is a minimal rest api client.
+type Client struct {
+	// HTTP client used to communicate with the api. If nil,
+	// DefaultClient is used.
+	Client *http.Client
+
+	// Dumper is used to dump the http.Request and
+	// http.Response for debug purposes.
+	Dumper logger.Dumper
+
+	// Authorize is used to authorize the http.Request.
+	Authorize func(*http.Request) error
+}
+
+// Error represents a failed api request.
+type Error struct {
+	Status  int
+	Message string
+	Header  http.Header
+}
+
+// NewError returns an api error for the failed
+// http.Response. The message is decoded from the json
+// response body, if present.
+func NewError(res *http.Response) *Error {
+	out := struct {
+		Message string `json:"message"`
+	}{}
+	json.NewDecoder(res.Body).Decode(&out)
+	return &Error{
+		Status:  res.StatusCode,
+		Message: out.Message,
+		Header:  res.Header,
+	}
+}
+
+// Error returns the string representation of the failed
+// api request.
+func (e *Error) Error() string {
+	return http.StatusText(e.Status)
+}
+
+// OTPRequired reports whether the request was rejected
+// because the user account requires a one-time password.
+// The server indicates this with an OTP response header,
+// such as X-GitHub-OTP, or with the error message.
+func (e *Error) OTPRequired() bool {
+	if e.Status != http.StatusUnauthorized && e.Status != http.StatusForbidden {
+		return false
+	}
+	for key, values := range e.Header {
+		if !strings.HasSuffix(key, "-Otp") {
+			continue
+		}
+		for _, value := range values {
+			if strings.HasPrefix(strings.ToLower(value), "required") {
+				return true
+			}
+		}
+	}
+	message := strings.ToLower(e.Message)
+	return strings.Contains(message, "otp") ||
+		strings.Contains(message, "two-factor") ||
+		strings.Contains(message, "2fa")
+}
+
+// Get performs a GET request to the path and decodes the
+// json response body into out.
+func (c *Client) Get(ctx context.Context, path string, out interface{}) error {
+	return c.Do(ctx, "GET", path, nil, out)
+}
+
+// Do performs the request and decodes the json response
+// body into out. An *Error is returned if the server
+// responds with a non-2xx status code.
+func (c *Client) Do(ctx context.Context, method, path string, in, out interface{}) error {
+	req, err := c.NewRequest(ctx, method, path, in)
+	if err != nil {
+		return err
+	}
+	res, err := c.Send(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode > 299 {
+		return NewError(res)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(res.Body).Decode(out)
+}
+
+// NewRequest returns a new authorized http.Request. If in
+// is non-nil it is encoded as the json request body.
+func (c *Client) NewRequest(ctx context.Context, method, path string, in interface{}) (*http.Request, error) {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, path, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.Authorize != nil {
+		if err := c.Authorize(req); err != nil {
+			return nil, err
+		}
+	}
+	return req, nil
+}
+
+// Send sends the http.Request and returns the
+// http.Response.
+func (c *Client) Send(req *http.Request) (*http.Response, error) {
+	if c.Dumper != nil {
+		c.Dumper.DumpRequest(req)
+	}
+	res, err := c.client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if c.Dumper != nil {
+		c.Dumper.DumpResponse(res)
+	}
+	return res, nil
+}
+
+func (c *Client) client() *http.Client {
+	if c.Client == nil {
+		return http.DefaultClient
+	}
+	return c.Client
+}
+
+// Bearer returns a function that authorizes the request
+// with the bearer token.
+func Bearer(token string) func(*http.Request) error {
+	return func(req *http.Request) error {
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	}
+}
+
+// Basic returns a function that authorizes the request
+// with the username and password.
+func Basic(username, password string) func(*http.Request) error {
+	return func(req *http.Request) error {
+		req.SetBasicAuth(username, password)
+		return nil
+	}
+}
+
+// Token returns a function that authorizes the request
+// with the token authorization scheme used by Gogs and
+// Gitea.
+func Token(token string) func(*http.Request) error {
+	return func(req *http.Request) error {
+		req.Header.Set("Authorization", "token "+token)
+		return nil
+	}
+}
+
+// Wrap wraps the error in a login error of the given kind.
+// Errors that occur while connecting to the server are
+// wrapped in a login error of kind ErrUnreachable.
+func Wrap(kind, err error) error {
+	if e, ok := err.(*login.Error); ok {
+		return e
+	}
+	if _, ok := err.(*url.Error); ok {
+		kind = login.ErrUnreachable
+	}
+	return &login.Error{Kind: kind, Err: err}
+}
+
+// Introspect converts the result of identifying the user
+// that authorized a token to the token details, for
+// providers without a token introspection endpoint. A
+// token rejected by the provider is inactive. The
+// authenticated user endpoint does not report the token
+// scopes or expiry, so these are left empty.
+func Introspect(user *login.User, err error) (*login.TokenInfo, error) {
+	if err == nil {
+		return &login.TokenInfo{Active: true, User: user}, nil
+	}
+	var e *Error
+	if errors.As(err, &e) && e.Status == http.StatusUnauthorized {
+		return &login.TokenInfo{Active: false}, nil
+	}
+	if e, ok := err.(*login.Error); ok && e.Kind == login.ErrIdentity {
+		return nil, &login.Error{Kind: login.ErrIntrospection, Err: e.Err}
+	}
+	return nil, err
+}
+
+// Host returns the host component of the server address.
+func Host(server string) string {
+	u, err := url.Parse(server)
+	if err != nil {
+		return server
+	}
+	return u.Host
+}
@@ -0,0 +1,101 @@
+// Copyright 2017 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/drone/go-login/login"
+	"github.com/h2non/gock"
+)
+
+func TestGet(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://gitea.company.com").
+		Get("/api/v1/user").
+		MatchHeader("Authorization", "token 755bb80e5b").
+		MatchHeader("Accept", "application/json").
+		Reply(200).
+		JSON(map[string]string{"login": "janedoe"})
+
+	client := &Client{Authorize: Token("755bb80e5b")}
+	out := map[string]string{}
+	err := client.Get(context.Background(), "https://gitea.company.com/api/v1/user", &out)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := out["login"], "janedoe"; got != want {
+		t.Errorf("Want login %s, got %s", want, got)
+	}
+}
+
+func TestGetError(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://gitea.company.com").
+		Get("/api/v1/user").
+		Reply(401)
+
+	client := &Client{Authorize: Bearer("755bb80e5b")}
+	err := client.Get(context.Background(), "https://gitea.company.com/api/v1/user", nil)
+	if e, ok := err.(*Error); !ok || e.Status != 401 {
+		t.Errorf("Want api error with status 401, got %v", err)
+	}
+}
+
+func TestOTPRequired(t *testing.T) {
+	tests := []struct {
+		err  *Error
+		want bool
+	}{
+		{err: &Error{Status: 401, Header: http.Header{"X-Github-Otp": {"required; app"}}}, want: true},
+		{err: &Error{Status: 401, Message: "Two-factor authentication is enabled"}, want: true},
+		{err: &Error{Status: 401, Message: "Unauthorized"}, want: false},
+		{err: &Error{Status: 404, Header: http.Header{"X-Gitea-Otp": {"required"}}}, want: false},
+	}
+	for _, test := range tests {
+		if got := test.err.OTPRequired(); got != test.want {
+			t.Errorf("Want OTPRequired %v for %+v, got %v", test.want, test.err, got)
+		}
+	}
+}
+
+func TestWrap(t *testing.T) {
+	err := Wrap(login.ErrIdentity, &Error{Status: 401})
+	if !errors.Is(err, login.ErrIdentity) {
+		t.Errorf("Want error %v, got %v", login.ErrIdentity, err)
+	}
+	err = Wrap(login.ErrIdentity, &url.Error{Op: "Get", Err: errors.New("connection refused")})
+	if !errors.Is(err, login.ErrUnreachable) {
+		t.Errorf("Want error %v, got %v", login.ErrUnreachable, err)
+	}
+}
+
+func TestIntrospect(t *testing.T) {
+	info, err := Introspect(&login.User{Login: "janedoe"}, nil)
+	if err != nil || !info.Active {
+		t.Errorf("Want active token, got %v", err)
+	}
+	info, err = Introspect(nil, Wrap(login.ErrIdentity, &Error{Status: 401}))
+	if err != nil || info.Active {
+		t.Errorf("Want inactive token, got %v", err)
+	}
+	_, err = Introspect(nil, Wrap(login.ErrIdentity, &Error{Status: 500}))
+	if !errors.Is(err, login.ErrIntrospection) {
+		t.Errorf("Want error %v, got %v", login.ErrIntrospection, err)
+	}
+}
+
+func TestHost(t *testing.T) {
+	if got, want := Host("https://github.company.com:8443"), "github.company.com:8443"; got != want {
+		t.Errorf("Want host %s, got %s", want, got)
+	}
+}
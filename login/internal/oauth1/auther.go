@@ -97,6 +97,26 @@ func (a *auther) setAccessTokenAuthHeader(req *http.Request, requestToken, reque
 	return nil
 }
 
+// setRequestAuthHeader sets the OAuth1 header for a request
+// to a protected resource using the token credentials
+// according to RFC 5849 3.1.
+func (a *auther) setRequestAuthHeader(req *http.Request, token, tokenSecret string) error {
+	oauthParams := a.commonOAuthParams()
+	oauthParams[oauthTokenParam] = token
+	params, err := collectParameters(req, oauthParams)
+	if err != nil {
+		return err
+	}
+	signatureBase := signatureBase(req, params)
+	signature, err := a.signer().Sign(tokenSecret, signatureBase)
+	if err != nil {
+		return err
+	}
+	oauthParams[oauthSignatureParam] = signature
+	req.Header.Set(authorizationHeaderParam, authHeaderValue(oauthParams))
+	return nil
+}
+
 // commonOAuthParams returns a map of the common OAuth1 protocol parameters,
 // excluding the oauth_signature parameter.
 func (a *auther) commonOAuthParams() map[string]string {
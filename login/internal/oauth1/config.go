@@ -9,8 +9,10 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
-	"net/http/httputil"
 	"net/url"
+
+	"github.com/drone/go-login/login"
+	"github.com/drone/go-login/login/logger"
 )
 
 // token stores the authorization credentials used to
@@ -59,6 +61,25 @@ type Config struct {
 	// The URL used to exchange the User-authorized
 	// Request Token for an Access Token.
 	AuthorizationURL string
+
+	// Identifier is used to retrieve the authenticated user
+	// after the token exchange. If nil, the user is not
+	// retrieved.
+	Identifier login.Identifier
+
+	// Logger is used to log the authorization flow. If
+	// nil the default noop logger is used.
+	Logger logger.Logger
+
+	// Dumper is used to dump the http.Request and
+	// http.Response for debug purposes.
+	Dumper logger.Dumper
+}
+
+// SignRequest signs the request to a protected resource
+// with the token credentials.
+func (c *Config) SignRequest(req *http.Request, t *login.Token) error {
+	return newAuther(c).setRequestAuthHeader(req, t.Access, t.Refresh)
 }
 
 // authorizeRedirect returns a client authorization
@@ -92,14 +113,13 @@ func (c *Config) requestToken() (*token, error) {
 	if err != nil {
 		return nil, err
 	}
-	res, err := c.client().Do(req)
+	res, err := c.do(req)
 	if err != nil {
-		return nil, err
+		return nil, &login.Error{Kind: login.ErrUnreachable, Err: err}
 	}
 	defer res.Body.Close()
 	if res.StatusCode > 300 {
-		// TODO(bradrydzewski) unmarshal the oauth1 error.
-		return nil, errors.New("Invalid Response")
+		return nil, parseError(res.Body)
 	}
 	return parseToken(res.Body)
 }
@@ -122,20 +142,33 @@ func (c *Config) authorizeToken(token, verifier string) (*token, error) {
 	if err != nil {
 		return nil, err
 	}
-	res, err := c.client().Do(req)
+	res, err := c.do(req)
 	if err != nil {
-		return nil, err
+		return nil, &login.Error{Kind: login.ErrUnreachable, Err: err}
 	}
 	defer res.Body.Close()
 	if res.StatusCode > 300 {
-		x, _ := httputil.DumpResponse(res, true)
-		println(string(x))
-		// TODO(bradrydzewski) unmarshal the oauth1 error.
-		return nil, errors.New("Invalid Response")
+		return nil, parseError(res.Body)
 	}
 	return parseToken(res.Body)
 }
 
+// do sends the http.Request and dumps the request and
+// response if a dumper is configured.
+func (c *Config) do(req *http.Request) (*http.Response, error) {
+	if c.Dumper != nil {
+		c.Dumper.DumpRequest(req)
+	}
+	res, err := c.client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if c.Dumper != nil {
+		c.Dumper.DumpResponse(res)
+	}
+	return res, nil
+}
+
 func (c *Config) client() *http.Client {
 	client := c.Client
 	if client == nil {
@@ -158,3 +191,18 @@ func parseToken(r io.Reader) (*token, error) {
 		TokenSecret: v.Get("oauth_token_secret"),
 	}, nil
 }
+
+// parseError parses the oauth1 problem reporting parameters
+// from the response body and returns a login error.
+func parseError(r io.Reader) error {
+	err := &login.Error{
+		Kind: login.ErrTokenExchange,
+		Err:  errors.New("Invalid Response"),
+	}
+	b, _ := ioutil.ReadAll(r)
+	if v, perr := url.ParseQuery(string(b)); perr == nil {
+		err.Code = v.Get("oauth_problem")
+		err.Description = v.Get("oauth_problem_advice")
+	}
+	return err
+}
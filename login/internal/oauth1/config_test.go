@@ -3,3 +3,69 @@
 // license that can be found in the LICENSE file.
 
 package oauth1
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/drone/go-login/login"
+)
+
+func TestParseError(t *testing.T) {
+	body := "oauth_problem=token_rejected&oauth_problem_advice=The+token+has+expired"
+	err := parseError(strings.NewReader(body))
+	if !errors.Is(err, login.ErrTokenExchange) {
+		t.Errorf("Want error %v, got %v", login.ErrTokenExchange, err)
+	}
+	var e *login.Error
+	if !errors.As(err, &e) {
+		t.Errorf("Want login error")
+		return
+	}
+	if got, want := e.Code, "token_rejected"; got != want {
+		t.Errorf("Want error code %q, got %q", want, got)
+	}
+	if got, want := e.Description, "The token has expired"; got != want {
+		t.Errorf("Want error description %q, got %q", want, got)
+	}
+}
+
+// recordDumper records the dumped requests and responses.
+type recordDumper struct {
+	requests  []*http.Request
+	responses []*http.Response
+}
+
+func (d *recordDumper) DumpRequest(req *http.Request) {
+	d.requests = append(d.requests, req)
+}
+
+func (d *recordDumper) DumpResponse(res *http.Response) {
+	d.responses = append(d.responses, res)
+}
+
+func TestAuthorizeTokenDumper(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(401)
+		w.Write([]byte("oauth_problem=token_rejected"))
+	}))
+	defer ts.Close()
+
+	dumper := new(recordDumper)
+	c := &Config{
+		ConsumerKey:    "5163c01dea",
+		ConsumerSecret: "14c71a2a21",
+		AccessTokenURL: ts.URL + "/access-token",
+		Dumper:         dumper,
+	}
+	_, err := c.authorizeToken("3da5415599", "e08f3fa43e")
+	if !errors.Is(err, login.ErrTokenExchange) {
+		t.Errorf("Want error %v, got %v", login.ErrTokenExchange, err)
+	}
+	if len(dumper.requests) != 1 || len(dumper.responses) != 1 {
+		t.Errorf("Want request and response dumped")
+	}
+}
@@ -8,17 +8,20 @@ import (
 	"net/http"
 
 	"github.com/drone/go-login/login"
+	"github.com/drone/go-login/login/internal/api"
+	"github.com/drone/go-login/login/logger"
 )
 
 // Handler returns a Handler that runs h at the completion
 // of the oauth2 authorization flow.
 func Handler(h http.Handler, c *Config) http.Handler {
-	return &handler{next: h, conf: c}
+	return &handler{next: h, conf: c, logs: c.Logger}
 }
 
 type handler struct {
 	conf *Config
 	next http.Handler
+	logs logger.Logger
 }
 
 func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -26,18 +29,22 @@ func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	verifier := r.FormValue("oauth_verifier")
 	if verifier == "" {
+		h.logger().Debugln("oauth1: requesting temporary token")
 		token, err := h.conf.requestToken()
 		if err != nil {
+			h.logger().Errorf("oauth1: cannot request token: %s", err)
 			ctx = login.WithError(ctx, err)
 			h.next.ServeHTTP(w, r.WithContext(ctx))
 			return
 		}
 		redirectTo, err := h.conf.authorizeRedirect(token.Token)
 		if err != nil {
+			h.logger().Errorf("oauth1: cannot create authorization redirect: %s", err)
 			ctx = login.WithError(ctx, err)
 			h.next.ServeHTTP(w, r.WithContext(ctx))
 			return
 		}
+		h.logger().Debugln("oauth1: redirecting to the authorization server")
 		http.Redirect(w, r, redirectTo, 302)
 		return
 	}
@@ -47,19 +54,46 @@ func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// requests the access_token from the authorization server.
 	// If an error is encountered, write the error to the
 	// context and prceed with the next http.Handler in the chain.
+	h.logger().Debugln("oauth1: exchanging token for access token")
 	accessToken, err := h.conf.authorizeToken(token, verifier)
 	if err != nil {
+		h.logger().Errorf("oauth1: cannot exchange token: %s: %s", token, err)
 		ctx = login.WithError(ctx, err)
 		h.next.ServeHTTP(w, r.WithContext(ctx))
 		return
 	}
 
-	// converts the oauth2 token type to the internal Token
-	// type and attaches to the context.
-	ctx = login.WithToken(ctx, &login.Token{
+	// converts the oauth1 token type to the internal Token
+	// type.
+	result := &login.Token{
 		Access:  accessToken.Token,
 		Refresh: accessToken.TokenSecret,
-	})
+	}
+
+	// retrieves the authenticated user from the provider
+	// and attaches to the context. If an error is
+	// encountered, write the error to the context and
+	// proceed with the next http.Handler in the chain.
+	if h.conf.Identifier != nil {
+		user, err := h.conf.Identifier.Identify(ctx, result)
+		if err != nil {
+			h.logger().Errorf("oauth1: cannot identify user: %s", err)
+			ctx = login.WithError(ctx, api.Wrap(login.ErrIdentity, err))
+			h.next.ServeHTTP(w, r.WithContext(ctx))
+			return
+		}
+		ctx = login.WithUser(ctx, user)
+	}
+
+	// attaches the token to the context.
+	ctx = login.WithToken(ctx, result)
 
 	h.next.ServeHTTP(w, r.WithContext(ctx))
 }
+
+func (h *handler) logger() logger.Logger {
+	if h.logs == nil {
+		return logger.Discard()
+	}
+	return h.logs
+}
@@ -0,0 +1,88 @@
+// Copyright 2017 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package password
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/drone/go-login/login"
+	"github.com/drone/go-login/login/internal/api"
+	"github.com/drone/go-login/login/logger"
+)
+
+// MintFunc exchanges the username, password and optional
+// one-time password for an access token.
+type MintFunc func(ctx context.Context, user, pass, otp string) (string, error)
+
+// Handler is a http.Handler that exchanges the username
+// and password submitted in the login form for an access
+// token, and runs Next at the completion of the login
+// flow.
+type Handler struct {
+	// Next is the handler that runs at the completion of
+	// the login flow.
+	Next http.Handler
+
+	// Name is the provider name used in log messages.
+	Name string
+
+	// Login is the login form url. If the username or
+	// password is empty the user is redirected to the
+	// login form.
+	Login string
+
+	// Mint creates the access token.
+	Mint MintFunc
+
+	// Identifier is used to retrieve the authenticated
+	// user. If nil, the user is not retrieved.
+	Identifier login.Identifier
+
+	// Logger is used to log the login flow. If nil the
+	// default noop logger is used.
+	Logger logger.Logger
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user := r.FormValue("username")
+	pass := r.FormValue("password")
+	otp := r.FormValue("otp")
+	if (user == "" || pass == "") && h.Login != "" {
+		http.Redirect(w, r, h.Login, 303)
+		return
+	}
+	h.logger().Debugf("%s: creating access token for %s", h.Name, user)
+	access, err := h.Mint(ctx, user, pass, otp)
+	if err != nil {
+		h.logger().Errorf("%s: cannot create access token: %s", h.Name, err)
+		ctx = login.WithError(ctx, err)
+		h.Next.ServeHTTP(w, r.WithContext(ctx))
+		return
+	}
+	result := &login.Token{
+		Access: access,
+	}
+	if h.Identifier != nil {
+		user, err := h.Identifier.Identify(ctx, result)
+		if err != nil {
+			h.logger().Errorf("%s: cannot identify user: %s", h.Name, err)
+			ctx = login.WithError(ctx, api.Wrap(login.ErrIdentity, err))
+			h.Next.ServeHTTP(w, r.WithContext(ctx))
+			return
+		}
+		ctx = login.WithUser(ctx, user)
+	}
+	ctx = login.WithToken(ctx, result)
+	h.Next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+func (h *Handler) logger() logger.Logger {
+	if h.Logger == nil {
+		return logger.Discard()
+	}
+	return h.Logger
+}
@@ -0,0 +1,66 @@
+// Copyright 2017 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package password
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/drone/go-login/login"
+)
+
+func TestHandler(t *testing.T) {
+	var ctx context.Context
+	h := &Handler{
+		Next: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx = r.Context()
+		}),
+		Mint: func(ctx context.Context, user, pass, otp string) (string, error) {
+			if user != "janedoe" || pass != "password" || otp != "123456" {
+				return "", login.ErrInvalidCredentials
+			}
+			return "3da541559", nil
+		},
+	}
+
+	body := strings.NewReader("username=janedoe&password=password&otp=123456")
+	r := httptest.NewRequest("POST", "/login", body)
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+	if err := login.ErrorFrom(ctx); err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := login.TokenFrom(ctx).Access, "3da541559"; got != want {
+		t.Errorf("Want access token %s, got %s", want, got)
+	}
+
+	body = strings.NewReader("username=janedoe&password=wrong")
+	r = httptest.NewRequest("POST", "/login", body)
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+	if err := login.ErrorFrom(ctx); !errors.Is(err, login.ErrInvalidCredentials) {
+		t.Errorf("Want error %v, got %v", login.ErrInvalidCredentials, err)
+	}
+}
+
+func TestHandlerRedirect(t *testing.T) {
+	h := &Handler{
+		Next:  http.NotFoundHandler(),
+		Login: "/login/form",
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/login", nil))
+	if got, want := w.Code, 303; got != want {
+		t.Errorf("Want status code %d, got %d", want, got)
+	}
+	if got, want := w.Header().Get("Location"), "/login/form"; got != want {
+		t.Errorf("Want redirect to %s, got %s", want, got)
+	}
+}
@@ -18,11 +18,141 @@ type Middleware interface {
 	Handler(h http.Handler) http.Handler
 }
 
+// Refresher refreshes an authorization token.
+type Refresher interface {
+	// Refresh exchanges the refresh token for a new
+	// authorization token.
+	Refresh(ctx context.Context, token *Token) (*Token, error)
+}
+
+// Identifier identifies the user that authorized a token.
+type Identifier interface {
+	// Identify returns the user that authorized the
+	// token.
+	Identify(ctx context.Context, token *Token) (*User, error)
+}
+
+// Authorizer authorizes the user that authorized a token.
+type Authorizer interface {
+	// Authorize returns an error if the user that
+	// authorized the token is not permitted to login.
+	Authorize(ctx context.Context, token *Token) error
+}
+
+// Revoker revokes authorization tokens.
+type Revoker interface {
+	// Revoke revokes the token. ErrUnsupported is
+	// returned if the provider does not support token
+	// revocation.
+	Revoke(ctx context.Context, token *Token) error
+}
+
+// Introspector returns the details of authorization
+// tokens.
+type Introspector interface {
+	// Introspect checks the token against the provider
+	// and returns the token details. A token that is
+	// expired or revoked is reported as inactive.
+	Introspect(ctx context.Context, token *Token) (*TokenInfo, error)
+}
+
+// DeviceAuthorizer authorizes devices that cannot receive
+// the authorization callback, such as command line tools,
+// using the device authorization grant (RFC 8628).
+type DeviceAuthorizer interface {
+	// DeviceCode requests a device code. The user code
+	// and verification uri are displayed to the user.
+	DeviceCode(ctx context.Context) (*DeviceCode, error)
+
+	// DeviceToken polls the token endpoint until the
+	// user authorizes the device, and returns the token.
+	DeviceToken(ctx context.Context, code *DeviceCode) (*Token, error)
+}
+
+// DeviceCode represents a device authorization request.
+type DeviceCode struct {
+	// DeviceCode is the device verification code.
+	DeviceCode string
+
+	// UserCode is the code the user enters at the
+	// verification uri.
+	UserCode string
+
+	// VerificationURI is the url the user visits to
+	// authorize the device.
+	VerificationURI string
+
+	// VerificationURIComplete is the verification uri
+	// that includes the user code, if provided.
+	VerificationURIComplete string
+
+	// Expires is the device code expiry.
+	Expires time.Time
+
+	// Interval is the minimum time to wait between
+	// polling requests.
+	Interval time.Duration
+}
+
 // Token represents an authorization token.
 type Token struct {
 	Access  string
 	Refresh string
 	Expires time.Time
+
+	// Type is the token type, such as bearer.
+	Type string
+
+	// Scopes are the scopes granted to the token.
+	Scopes []string
+
+	// ScopesReported reports whether the provider reported
+	// the granted scopes. A token granted no scopes has
+	// empty Scopes and ScopesReported set to true.
+	ScopesReported bool
+}
+
+// TokenInfo describes an authorization token.
+type TokenInfo struct {
+	// Active reports whether the token is valid.
+	Active bool
+
+	// Scopes are the scopes granted to the token, if
+	// reported by the provider.
+	Scopes []string
+
+	// Expires is the token expiry, if reported by the
+	// provider.
+	Expires time.Time
+
+	// User is the user that authorized the token.
+	User *User
+}
+
+// User represents the authenticated user.
+type User struct {
+	ID     string
+	Login  string
+	Name   string
+	Email  string
+	Avatar string
+	Host   string
+}
+
+// Upgrade describes a request for additional scopes for a
+// user that is already logged in.
+type Upgrade struct {
+	// Scopes are the additional scopes requested.
+	Scopes []string
+
+	// LoginHint is the login of the user, used by
+	// providers that support a login hint to skip
+	// account selection.
+	LoginHint string
+
+	// Prompt is the prompt parameter, used by providers
+	// that support it to force the consent screen.
+	Prompt string
 }
 
 type key int
@@ -30,6 +160,11 @@ type key int
 const (
 	tokenKey key = iota
 	errorKey
+	returnToKey
+	userKey
+	upgradeKey
+	providerKey
+	serverKey
 )
 
 // WithToken returns a parent context with the token.
@@ -42,14 +177,87 @@ func WithError(parent context.Context, err error) context.Context {
 	return context.WithValue(parent, errorKey, err)
 }
 
+// WithReturnTo returns a parent context with the url the
+// user requested before the authorization flow started.
+func WithReturnTo(parent context.Context, url string) context.Context {
+	return context.WithValue(parent, returnToKey, url)
+}
+
+// WithUser returns a parent context with the user.
+func WithUser(parent context.Context, user *User) context.Context {
+	return context.WithValue(parent, userKey, user)
+}
+
+// WithUpgrade returns a parent context with the upgrade.
+// If the context passed to a provider handler includes an
+// upgrade, the authorization request includes the
+// additional scopes. The handler attaches the upgrade to
+// the context at the completion of the authorization flow,
+// which indicates the token is an upgraded token.
+func WithUpgrade(parent context.Context, upgrade *Upgrade) context.Context {
+	return context.WithValue(parent, upgradeKey, upgrade)
+}
+
+// WithProvider returns a parent context with the name of
+// the provider that handled the request.
+func WithProvider(parent context.Context, name string) context.Context {
+	return context.WithValue(parent, providerKey, name)
+}
+
+// WithServer returns a parent context with the address of
+// the server that issued the token.
+func WithServer(parent context.Context, server string) context.Context {
+	return context.WithValue(parent, serverKey, server)
+}
+
 // TokenFrom returns the login token rom the context.
 func TokenFrom(ctx context.Context) *Token {
 	token, _ := ctx.Value(tokenKey).(*Token)
 	return token
 }
 
-// ErrorFrom returns the login error from the context.
+// ErrorFrom returns the login error from the context. The
+// error returned by the providers in this module is an
+// *Error that can be inspected using errors.Is and
+// errors.As.
 func ErrorFrom(ctx context.Context) error {
 	err, _ := ctx.Value(errorKey).(error)
 	return err
 }
+
+// ReturnToFrom returns the url the user requested before
+// the authorization flow started, or an empty string if
+// no url was provided.
+func ReturnToFrom(ctx context.Context) string {
+	url, _ := ctx.Value(returnToKey).(string)
+	return url
+}
+
+// UserFrom returns the authenticated user from the context.
+func UserFrom(ctx context.Context) *User {
+	user, _ := ctx.Value(userKey).(*User)
+	return user
+}
+
+// UpgradeFrom returns the upgrade from the context, or nil
+// if the authorization is not an upgrade.
+func UpgradeFrom(ctx context.Context) *Upgrade {
+	upgrade, _ := ctx.Value(upgradeKey).(*Upgrade)
+	return upgrade
+}
+
+// ProviderFrom returns the name of the provider that
+// handled the request, or an empty string if the request
+// was not routed by a Mux.
+func ProviderFrom(ctx context.Context) string {
+	name, _ := ctx.Value(providerKey).(string)
+	return name
+}
+
+// ServerFrom returns the address of the server that issued
+// the token, or an empty string if the provider does not
+// report the server.
+func ServerFrom(ctx context.Context) string {
+	server, _ := ctx.Value(serverKey).(string)
+	return server
+}
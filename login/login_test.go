@@ -37,3 +37,56 @@ func TestWithToken(t *testing.T) {
 		t.Errorf("Expect nil error in context")
 	}
 }
+
+func TestWithReturnTo(t *testing.T) {
+	ctx := context.Background()
+	ctx = WithReturnTo(ctx, "/octocat/hello-world")
+	if got, want := ReturnToFrom(ctx), "/octocat/hello-world"; got != want {
+		t.Errorf("Want return url %q, got %q", want, got)
+	}
+
+	ctx = context.Background()
+	if ReturnToFrom(ctx) != "" {
+		t.Errorf("Expect empty return url in context")
+	}
+}
+
+func TestWithUser(t *testing.T) {
+	user := &User{Login: "octocat"}
+	ctx := context.Background()
+	ctx = WithUser(ctx, user)
+	if UserFrom(ctx) != user {
+		t.Errorf("Expect user stored in context")
+	}
+
+	ctx = context.Background()
+	if UserFrom(ctx) != nil {
+		t.Errorf("Expect nil user in context")
+	}
+}
+
+func TestWithProvider(t *testing.T) {
+	ctx := context.Background()
+	ctx = WithProvider(ctx, "github")
+	if got, want := ProviderFrom(ctx), "github"; got != want {
+		t.Errorf("Want provider %q, got %q", want, got)
+	}
+
+	ctx = context.Background()
+	if ProviderFrom(ctx) != "" {
+		t.Errorf("Expect empty provider in context")
+	}
+}
+
+func TestWithServer(t *testing.T) {
+	ctx := context.Background()
+	ctx = WithServer(ctx, "https://github.com")
+	if got, want := ServerFrom(ctx), "https://github.com"; got != want {
+		t.Errorf("Want server %q, got %q", want, got)
+	}
+
+	ctx = context.Background()
+	if ServerFrom(ctx) != "" {
+		t.Errorf("Expect empty server in context")
+	}
+}
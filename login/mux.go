@@ -0,0 +1,133 @@
+// Copyright 2017 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package login
+
+import (
+	"html/template"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+var _ Middleware = (*Mux)(nil)
+
+// default mux settings.
+const defaultPrefix = "/login"
+
+// DefaultChooser is a minimal provider chooser page. The
+// template is executed with a slice of ChooserItem.
+var DefaultChooser = template.Must(template.New("chooser").Parse(`<!DOCTYPE html>
+<html>
+<body>
+<ul>
+{{range .}}<li><a href="{{.URL}}">{{.Name}}</a></li>
+{{end}}</ul>
+</body>
+</html>
+`))
+
+// ChooserItem describes a provider listed in the provider
+// chooser page.
+type ChooserItem struct {
+	Name string
+	URL  string
+}
+
+// Mux routes login requests to one of several named
+// providers. Requests to {prefix}/{provider} and
+// {prefix}/{provider}/callback are handled by the named
+// provider, and the provider name is available to the
+// downstream handler using ProviderFrom.
+type Mux struct {
+	// Prefix is the path prefix. The default prefix is
+	// /login.
+	Prefix string
+
+	// Chooser is rendered for requests to the prefix to
+	// let the user choose a provider. If nil, requests to
+	// the prefix return 404 Not Found.
+	Chooser *template.Template
+
+	providers map[string]Middleware
+}
+
+// Register registers the provider with the given name.
+// The provider redirect url should be set to
+// {prefix}/{name}/callback.
+func (m *Mux) Register(name string, provider Middleware) {
+	if m.providers == nil {
+		m.providers = map[string]Middleware{}
+	}
+	m.providers[name] = provider
+}
+
+// Handler returns a http.Handler that routes requests to
+// the named provider, and runs h at the completion of the
+// authorization flow.
+func (m *Mux) Handler(h http.Handler) http.Handler {
+	handlers := map[string]http.Handler{}
+	for name, provider := range m.providers {
+		handlers[name] = provider.Handler(h)
+	}
+	return &muxHandler{mux: m, handlers: handlers}
+}
+
+func (m *Mux) prefix() string {
+	if m.Prefix == "" {
+		return defaultPrefix
+	}
+	return strings.TrimSuffix(m.Prefix, "/")
+}
+
+type muxHandler struct {
+	mux      *Mux
+	handlers map[string]http.Handler
+}
+
+func (h *muxHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	prefix := h.mux.prefix()
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	if path == prefix {
+		h.choose(w, r)
+		return
+	}
+	if !strings.HasPrefix(path, prefix+"/") {
+		http.NotFound(w, r)
+		return
+	}
+	parts := strings.Split(path[len(prefix)+1:], "/")
+	if len(parts) > 2 || (len(parts) == 2 && parts[1] != "callback") {
+		http.NotFound(w, r)
+		return
+	}
+	name := parts[0]
+	next, ok := h.handlers[name]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	ctx := WithProvider(r.Context(), name)
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// choose renders the provider chooser page.
+func (h *muxHandler) choose(w http.ResponseWriter, r *http.Request) {
+	if h.mux.Chooser == nil {
+		http.NotFound(w, r)
+		return
+	}
+	var items []ChooserItem
+	for name := range h.handlers {
+		items = append(items, ChooserItem{
+			Name: name,
+			URL:  h.mux.prefix() + "/" + name,
+		})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Name < items[j].Name
+	})
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	h.mux.Chooser.Execute(w, items)
+}
@@ -0,0 +1,103 @@
+// Copyright 2017 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package login
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fakeProvider is a provider that completes the
+// authorization flow immediately.
+type fakeProvider struct {
+	token string
+}
+
+func (p *fakeProvider) Handler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := WithToken(r.Context(), &Token{Access: p.token})
+		h.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func TestMux(t *testing.T) {
+	mux := new(Mux)
+	mux.Register("github", &fakeProvider{token: "3da541559"})
+	mux.Register("gitlab", &fakeProvider{token: "9b2b4b1c7"})
+
+	var provider, token string
+	h := mux.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		provider = ProviderFrom(r.Context())
+		token = TokenFrom(r.Context()).Access
+	}))
+
+	tests := []struct {
+		path     string
+		provider string
+		token    string
+	}{
+		{"/login/github", "github", "3da541559"},
+		{"/login/gitlab/callback", "gitlab", "9b2b4b1c7"},
+	}
+	for _, test := range tests {
+		provider, token = "", ""
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", test.path, nil)
+		h.ServeHTTP(w, r)
+		if got, want := provider, test.provider; got != want {
+			t.Errorf("Want provider %q, got %q", want, got)
+		}
+		if got, want := token, test.token; got != want {
+			t.Errorf("Want token %q, got %q", want, got)
+		}
+	}
+}
+
+func TestMuxNotFound(t *testing.T) {
+	mux := &Mux{Prefix: "/auth"}
+	mux.Register("github", &fakeProvider{})
+	h := mux.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Expect handler not called for %s", r.URL.Path)
+	}))
+
+	for _, path := range []string{
+		"/auth",
+		"/auth/bitbucket",
+		"/auth/github/unknown",
+		"/login/github",
+	} {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", path, nil)
+		h.ServeHTTP(w, r)
+		if got, want := w.Code, 404; got != want {
+			t.Errorf("Want status code %d for %s, got %d", want, path, got)
+		}
+	}
+}
+
+func TestMuxChooser(t *testing.T) {
+	mux := &Mux{Chooser: DefaultChooser}
+	mux.Register("gitlab", &fakeProvider{})
+	mux.Register("github", &fakeProvider{})
+	h := mux.Handler(http.NotFoundHandler())
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/login", nil)
+	h.ServeHTTP(w, r)
+	if got, want := w.Code, 200; got != want {
+		t.Errorf("Want status code %d, got %d", want, got)
+	}
+	body := w.Body.String()
+	github := strings.Index(body, `<a href="/login/github">github</a>`)
+	gitlab := strings.Index(body, `<a href="/login/gitlab">gitlab</a>`)
+	if github == -1 || gitlab == -1 {
+		t.Errorf("Expect chooser links to each provider, got %s", body)
+	}
+	if github > gitlab {
+		t.Errorf("Expect providers sorted by name")
+	}
+}
@@ -0,0 +1,603 @@
+// Copyright 2017 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package oauth2 provides a generic OAuth2 authorization
+// middleware that can be used to implement login providers
+// for authorization servers not supported by this module.
+package oauth2
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/drone/go-login/login"
+	"github.com/drone/go-login/login/logger"
+)
+
+var (
+	_ login.Middleware       = (*Config)(nil)
+	_ login.Refresher        = (*Config)(nil)
+	_ login.Revoker          = (*Config)(nil)
+	_ login.Introspector     = (*Config)(nil)
+	_ login.DeviceAuthorizer = (*Config)(nil)
+)
+
+// AuthStyle represents how the client credentials are
+// sent to the token endpoint.
+type AuthStyle int
+
+const (
+	// AuthStyleHeader sends the client_id and client_secret
+	// using the HTTP Basic Authorization header. This is
+	// the default.
+	AuthStyleHeader AuthStyle = iota
+
+	// AuthStyleParams sends the client_id and client_secret
+	// in the POST body as form parameters.
+	AuthStyleParams
+
+	// AuthStyleAssertion sends the client_secret as a JWT
+	// bearer client assertion, and sends the authorization
+	// code and refresh token as the assertion parameter.
+	// This style is used by Azure DevOps.
+	AuthStyleAssertion
+)
+
+// grant and client assertion types defined by the JWT
+// bearer profile (RFC 7523).
+const (
+	grantTypeJWTBearer     = "urn:ietf:params:oauth:grant-type:jwt-bearer"
+	assertionTypeJWTBearer = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+)
+
+// token stores the authorization credentials used to
+// access protected resources.
+type token struct {
+	AccessToken  string  `json:"access_token"`
+	TokenType    string  `json:"token_type"`
+	RefreshToken string  `json:"refresh_token"`
+	Expires      expires `json:"expires_in"`
+	IDToken      string  `json:"id_token"`
+	Scope        scope   `json:"scope"`
+
+	// Scopes is the non-standard scopes field returned by
+	// Bitbucket in place of scope.
+	Scopes scope `json:"scopes"`
+}
+
+// scope is the list of scopes granted to the token. Some
+// authorization servers encode the scopes as a space or
+// comma separated string, others as an array. The list is
+// nil if the authorization server did not report the
+// scopes, and empty if no scopes were granted.
+type scope []string
+
+func (s *scope) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(b, &list); err == nil {
+		*s = append(scope{}, list...)
+		return nil
+	}
+	var str string
+	if err := json.Unmarshal(b, &str); err != nil {
+		return err
+	}
+	*s = append(scope{}, login.ParseScopes(str)...)
+	return nil
+}
+
+// introspection stores the token details returned by the
+// introspection endpoint.
+type introspection struct {
+	Active   bool   `json:"active"`
+	Scope    string `json:"scope"`
+	Expires  int64  `json:"exp"`
+	Subject  string `json:"sub"`
+	Username string `json:"username"`
+}
+
+// expires is the token lifetime in seconds. Some
+// authorization servers encode the lifetime as a string.
+type expires int64
+
+func (e *expires) UnmarshalJSON(b []byte) error {
+	s := strings.Trim(string(b), `"`)
+	if s == "" || s == "null" {
+		return nil
+	}
+	i, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return err
+	}
+	*e = expires(i)
+	return nil
+}
+
+// convert converts the oauth2 token to a login token.
+func (t *token) convert() *login.Token {
+	scopes := t.Scope
+	if scopes == nil {
+		scopes = t.Scopes
+	}
+	token := &login.Token{
+		Access:         t.AccessToken,
+		Refresh:        t.RefreshToken,
+		Type:           t.TokenType,
+		Scopes:         scopes,
+		ScopesReported: scopes != nil,
+	}
+	// the expiry is left empty if the authorization server
+	// does not report the token lifetime, in which case the
+	// token is not considered expired.
+	if t.Expires != 0 {
+		token.Expires = time.Now().UTC().Add(
+			time.Duration(t.Expires) * time.Second,
+		)
+	}
+	return token
+}
+
+// IDTokenVerifier verifies the OpenID Connect id_token.
+type IDTokenVerifier interface {
+	// Verify verifies the raw id_token was issued for the
+	// nonce and returns a copy of the parent context that
+	// includes the id_token claims.
+	Verify(ctx context.Context, raw, nonce string) (context.Context, error)
+}
+
+// Config stores the application configuration.
+type Config struct {
+	// HTTP client used to communicate with the authorization
+	// server. If nil, DefaultClient is used.
+	Client *http.Client
+
+	// ClientID is the identifier issued to the application
+	// during the registration process.
+	ClientID string
+
+	// ClientSecret is the secret issued to the application
+	// during the registration process.
+	ClientSecret string
+
+	// Scope is the scope of the access request.
+	Scope []string
+
+	// ScopeSeparator is used to join the scopes in the
+	// authorization request. If empty, the scopes are
+	// separated by a space.
+	ScopeSeparator string
+
+	// LoginHintParam is the name of the authorization
+	// request parameter used to send the login hint of a
+	// scope upgrade. If empty, login_hint is used.
+	LoginHintParam string
+
+	// RedirectURL is used by the authorization server to
+	// return the authorization credentials to the client.
+	RedirectURL string
+
+	// AccessTokenURL is used by the client to exchange an
+	// authorization grant for an access token.
+	AccessTokenURL string
+
+	// AuthorizationURL is used by the client to obtain
+	// authorization from the resource owner.
+	AuthorizationURL string
+
+	// RevocationURL is used by the client to revoke an
+	// access token or refresh token (RFC 7009). If empty,
+	// token revocation is not supported.
+	RevocationURL string
+
+	// DeviceAuthURL is used by the client to request a
+	// device code (RFC 8628). If empty, device
+	// authorization is not supported.
+	DeviceAuthURL string
+
+	// IntrospectionURL is used by the client to request
+	// the token details (RFC 7662). If empty, token
+	// introspection is not supported.
+	IntrospectionURL string
+
+	// AuthStyle specifies how the client credentials are
+	// sent to the token endpoint.
+	AuthStyle AuthStyle
+
+	// ResponseType is the response type of the authorization
+	// request. If empty, the code response type is used.
+	ResponseType string
+
+	// AuthParams are additional parameters sent with the
+	// authorization request. The parameters cannot override
+	// the parameters defined by the authorization flow.
+	AuthParams map[string]string
+
+	// RequestParams are the names of the login request
+	// query parameters that are copied to the authorization
+	// request, which allows AuthParams to be overridden per
+	// request. For example, login_hint can be used to
+	// pre-fill the account on re-authentication.
+	RequestParams []string
+
+	// PKCE instructs the client to use the Proof Key for
+	// Code Exchange extension (RFC 7636). A code verifier
+	// is generated for each authorization request and the
+	// S256 code challenge is sent to the authorization
+	// server.
+	PKCE bool
+
+	// StateStore is used to persist the authorization state
+	// between the authorization redirect and the callback.
+	// If nil, the state is persisted in a session cookie.
+	StateStore login.StateStore
+
+	// Server is the address of the server that issues the
+	// token. If set, the address is reported to the next
+	// handler using login.ServerFrom, and the state is
+	// isolated from handlers for other servers if the
+	// StateStore implements login.Namespacer.
+	Server string
+
+	// Identifier is used to retrieve the authenticated user
+	// after the token exchange. If nil, the user is not
+	// retrieved.
+	Identifier login.Identifier
+
+	// Authorizer is used to authorize the user after the
+	// token exchange. If nil, all users are authorized.
+	Authorizer login.Authorizer
+
+	// IDTokenVerifier is used to verify the OpenID Connect
+	// id_token returned by the token endpoint. If non-nil,
+	// a nonce is sent with the authorization request and
+	// the id_token is required. If nil, the id_token is
+	// ignored.
+	IDTokenVerifier IDTokenVerifier
+
+	// Logger is used to log errors. If nil the provider
+	// use the default noop logger.
+	Logger logger.Logger
+
+	// Dumper is used to dump the http.Request and
+	// http.Response for debug purposes.
+	Dumper logger.Dumper
+}
+
+// Handler returns a http.Handler that runs h at the
+// completion of the oauth2 authorization flow.
+func (c *Config) Handler(h http.Handler) http.Handler {
+	return Handler(h, c)
+}
+
+// authorizeRedirect returns a client authorization
+// redirect endpoint. If the authorization is an upgrade,
+// the additional scopes are requested.
+func (c *Config) authorizeRedirect(state *login.State, upgrade *login.Upgrade, query url.Values) string {
+	v := url.Values{}
+	for key, value := range c.AuthParams {
+		v.Set(key, value)
+	}
+	for _, key := range c.RequestParams {
+		if value := query.Get(key); len(value) != 0 {
+			v.Set(key, value)
+		}
+	}
+	scope := c.Scope
+	if upgrade != nil {
+		scope = merge(c.Scope, upgrade.Scopes)
+		if len(upgrade.LoginHint) != 0 {
+			v.Set(c.loginHintParam(), upgrade.LoginHint)
+		}
+		if len(upgrade.Prompt) != 0 {
+			v.Set("prompt", upgrade.Prompt)
+		}
+	}
+	v.Set("response_type", c.responseType())
+	v.Set("client_id", c.ClientID)
+	if len(scope) != 0 {
+		v.Set("scope", strings.Join(scope, c.scopeSeparator()))
+	}
+	if len(state.Value) != 0 {
+		v.Set("state", state.Value)
+	}
+	if len(c.RedirectURL) != 0 {
+		v.Set("redirect_uri", c.RedirectURL)
+	}
+	if len(state.Verifier) != 0 {
+		v.Set("code_challenge", challenge(state.Verifier))
+		v.Set("code_challenge_method", "S256")
+	}
+	if len(state.Nonce) != 0 {
+		v.Set("nonce", state.Nonce)
+	}
+	u, _ := url.Parse(c.AuthorizationURL)
+	u.RawQuery = v.Encode()
+	return u.String()
+}
+
+// exchange converts an authorization code into a token.
+func (c *Config) exchange(ctx context.Context, code, state, verifier string) (*token, error) {
+	v := url.Values{
+		"grant_type": {"authorization_code"},
+		"code":       {code},
+	}
+	if len(state) != 0 {
+		v.Set("state", state)
+	}
+	if len(c.RedirectURL) != 0 {
+		v.Set("redirect_uri", c.RedirectURL)
+	}
+	if len(verifier) != 0 {
+		v.Set("code_verifier", verifier)
+	}
+	return c.token(ctx, v)
+}
+
+// Refresh exchanges the refresh token for a new token
+// using the refresh_token grant.
+func (c *Config) Refresh(ctx context.Context, t *login.Token) (*login.Token, error) {
+	if t == nil || len(t.Refresh) == 0 {
+		return nil, ErrRefresh
+	}
+	v := url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {t.Refresh},
+	}
+	if len(c.RedirectURL) != 0 {
+		v.Set("redirect_uri", c.RedirectURL)
+	}
+	source, err := c.token(ctx, v)
+	if err != nil {
+		return nil, exchangeError(err)
+	}
+	token := source.convert()
+	// the authorization server may choose not to issue a
+	// new refresh token, in which case the client retains
+	// the existing refresh token.
+	if len(token.Refresh) == 0 {
+		token.Refresh = t.Refresh
+	}
+	// the granted scopes are unchanged if the authorization
+	// server does not report the scopes.
+	if !token.ScopesReported {
+		token.Scopes = t.Scopes
+		token.ScopesReported = t.ScopesReported
+	}
+	return token, nil
+}
+
+// Revoke revokes the access token and refresh token using
+// the token revocation endpoint (RFC 7009).
+func (c *Config) Revoke(ctx context.Context, t *login.Token) error {
+	if len(c.RevocationURL) == 0 {
+		return login.ErrUnsupported
+	}
+	if t == nil {
+		return login.ErrToken
+	}
+	if len(t.Refresh) != 0 {
+		if err := c.revoke(ctx, t.Refresh, "refresh_token"); err != nil {
+			return revocationError(err)
+		}
+	}
+	if len(t.Access) != 0 {
+		if err := c.revoke(ctx, t.Access, "access_token"); err != nil {
+			return revocationError(err)
+		}
+	}
+	return nil
+}
+
+// Introspect returns the access token details using the
+// token introspection endpoint (RFC 7662).
+func (c *Config) Introspect(ctx context.Context, t *login.Token) (*login.TokenInfo, error) {
+	if len(c.IntrospectionURL) == 0 {
+		return nil, login.ErrUnsupported
+	}
+	if t == nil {
+		return nil, login.ErrToken
+	}
+	v := url.Values{
+		"token":           {t.Access},
+		"token_type_hint": {"access_token"},
+	}
+	res, err := c.post(ctx, c.IntrospectionURL, v)
+	if err != nil {
+		return nil, introspectionError(err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode > 299 {
+		err := new(Error)
+		json.NewDecoder(res.Body).Decode(err)
+		return nil, introspectionError(err)
+	}
+
+	out := new(introspection)
+	if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+		return nil, introspectionError(err)
+	}
+	if !out.Active {
+		return &login.TokenInfo{Active: false}, nil
+	}
+	info := &login.TokenInfo{Active: true}
+	if len(out.Scope) != 0 {
+		info.Scopes = login.ParseScopes(out.Scope)
+	}
+	if out.Expires != 0 {
+		info.Expires = time.Unix(out.Expires, 0).UTC()
+	}
+	if len(out.Subject) != 0 || len(out.Username) != 0 {
+		info.User = &login.User{
+			ID:    out.Subject,
+			Login: out.Username,
+		}
+	}
+	return info, nil
+}
+
+// revoke requests the revocation endpoint revoke the
+// token.
+func (c *Config) revoke(ctx context.Context, token, hint string) error {
+	v := url.Values{
+		"token":           {token},
+		"token_type_hint": {hint},
+	}
+	res, err := c.post(ctx, c.RevocationURL, v)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode > 299 {
+		err := new(Error)
+		json.NewDecoder(res.Body).Decode(err)
+		return err
+	}
+	return nil
+}
+
+// token requests a token from the token endpoint using
+// the provided grant parameters.
+func (c *Config) token(ctx context.Context, v url.Values) (*token, error) {
+	if c.AuthStyle == AuthStyleAssertion {
+		v = assertion(v)
+	}
+	res, err := c.post(ctx, c.AccessTokenURL, v)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode > 299 {
+		err := new(Error)
+		json.NewDecoder(res.Body).Decode(err)
+		return nil, err
+	}
+
+	// some authorization servers, including GitHub, return
+	// the error with a 200 status code.
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	erro := new(Error)
+	json.Unmarshal(body, erro)
+	if len(erro.Code) != 0 {
+		return nil, erro
+	}
+
+	token := &token{}
+	err = json.Unmarshal(body, token)
+	return token, err
+}
+
+// post sends the form parameters to the endpoint,
+// authenticated with the client credentials.
+func (c *Config) post(ctx context.Context, endpoint string, v url.Values) (*http.Response, error) {
+	switch {
+	case len(c.ClientSecret) == 0:
+		// public clients, such as command line tools,
+		// identify with the client_id and do not
+		// authenticate.
+		v.Set("client_id", c.ClientID)
+	case c.AuthStyle == AuthStyleParams:
+		v.Set("client_id", c.ClientID)
+		v.Set("client_secret", c.ClientSecret)
+	case c.AuthStyle == AuthStyleAssertion:
+		v.Set("client_assertion_type", assertionTypeJWTBearer)
+		v.Set("client_assertion", c.ClientSecret)
+	}
+
+	req, err := http.NewRequest("POST", endpoint, strings.NewReader(v.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	if c.AuthStyle == AuthStyleHeader && len(c.ClientSecret) != 0 {
+		req.SetBasicAuth(c.ClientID, c.ClientSecret)
+	}
+
+	if c.Dumper != nil {
+		c.Dumper.DumpRequest(req)
+	}
+
+	res, err := c.client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Dumper != nil {
+		c.Dumper.DumpResponse(res)
+	}
+	return res, nil
+}
+
+func (c *Config) responseType() string {
+	if c.ResponseType == "" {
+		return "code"
+	}
+	return c.ResponseType
+}
+
+func (c *Config) scopeSeparator() string {
+	if c.ScopeSeparator == "" {
+		return " "
+	}
+	return c.ScopeSeparator
+}
+
+func (c *Config) loginHintParam() string {
+	if c.LoginHintParam == "" {
+		return "login_hint"
+	}
+	return c.LoginHintParam
+}
+
+func (c *Config) stateStore() login.StateStore {
+	var store login.StateStore = new(login.CookieStore)
+	if c.StateStore != nil {
+		store = c.StateStore
+	}
+	if ns, ok := store.(login.Namespacer); ok && len(c.Server) != 0 {
+		return ns.Namespace(namespace(c.Server))
+	}
+	return store
+}
+
+func (c *Config) client() *http.Client {
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return client
+}
+
+// assertion converts the grant parameters to the JWT
+// bearer assertion format. The authorization code and the
+// refresh token are sent as the assertion parameter.
+func assertion(v url.Values) url.Values {
+	switch v.Get("grant_type") {
+	case "authorization_code":
+		v.Set("grant_type", grantTypeJWTBearer)
+		v.Set("assertion", v.Get("code"))
+		v.Del("code")
+	case "refresh_token":
+		v.Set("assertion", v.Get("refresh_token"))
+		v.Del("refresh_token")
+	}
+	return v
+}
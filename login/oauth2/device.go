@@ -0,0 +1,121 @@
+// Copyright 2017 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package oauth2
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/drone/go-login/login"
+)
+
+// grantTypeDeviceCode is the device code grant type
+// defined by RFC 8628.
+const grantTypeDeviceCode = "urn:ietf:params:oauth:grant-type:device_code"
+
+// defaultInterval is the polling interval used if the
+// authorization server does not specify an interval.
+const defaultInterval = 5 * time.Second
+
+// after waits for the duration to elapse. It is a
+// variable so that it can be replaced in unit tests.
+var after = time.After
+
+// deviceCode stores the device authorization response.
+type deviceCode struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURL         string `json:"verification_url"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	Expires                 int64  `json:"expires_in"`
+	Interval                int64  `json:"interval"`
+}
+
+// DeviceCode requests a device code from the device
+// authorization endpoint (RFC 8628).
+func (c *Config) DeviceCode(ctx context.Context) (*login.DeviceCode, error) {
+	if len(c.DeviceAuthURL) == 0 {
+		return nil, login.ErrUnsupported
+	}
+	v := url.Values{}
+	if len(c.Scope) != 0 {
+		v.Set("scope", strings.Join(c.Scope, c.scopeSeparator()))
+	}
+	res, err := c.post(ctx, c.DeviceAuthURL, v)
+	if err != nil {
+		return nil, deviceError(err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode > 299 {
+		err := new(Error)
+		json.NewDecoder(res.Body).Decode(err)
+		return nil, deviceError(err)
+	}
+
+	out := new(deviceCode)
+	if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+		return nil, deviceError(err)
+	}
+	code := &login.DeviceCode{
+		DeviceCode:              out.DeviceCode,
+		UserCode:                out.UserCode,
+		VerificationURI:         out.VerificationURI,
+		VerificationURIComplete: out.VerificationURIComplete,
+		Interval:                time.Duration(out.Interval) * time.Second,
+	}
+	if len(code.VerificationURI) == 0 {
+		code.VerificationURI = out.VerificationURL
+	}
+	if out.Expires != 0 {
+		code.Expires = time.Now().Add(time.Duration(out.Expires) * time.Second)
+	}
+	return code, nil
+}
+
+// DeviceToken polls the token endpoint until the user
+// authorizes the device, the user denies the request, or
+// the device code expires.
+func (c *Config) DeviceToken(ctx context.Context, code *login.DeviceCode) (*login.Token, error) {
+	interval := code.Interval
+	if interval == 0 {
+		interval = defaultInterval
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-after(interval):
+		}
+		if !code.Expires.IsZero() && time.Now().After(code.Expires) {
+			return nil, &login.Error{Kind: login.ErrTokenExchange, Code: "expired_token"}
+		}
+		v := url.Values{
+			"grant_type":  {grantTypeDeviceCode},
+			"device_code": {code.DeviceCode},
+			"client_id":   {c.ClientID},
+		}
+		source, err := c.token(ctx, v)
+		if e, ok := err.(*Error); ok {
+			switch e.Code {
+			case "authorization_pending":
+				continue
+			case "slow_down":
+				interval += 5 * time.Second
+				continue
+			case "access_denied":
+				return nil, authorizationError(e)
+			}
+		}
+		if err != nil {
+			return nil, exchangeError(err)
+		}
+		return source.convert(), nil
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright 2017 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package oauth2
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/drone/go-login/login"
+	"github.com/h2non/gock"
+)
+
+func TestDeviceCode(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://github.com").
+		Post("/login/device/code").
+		SetMatcher(gock.NewMatcher()).
+		BodyString("client_id=5163c01dea&scope=repo\\+user").
+		Reply(200).
+		JSON(map[string]interface{}{
+			"device_code":      "3584d83530",
+			"user_code":        "WDJB-MJHT",
+			"verification_uri": "https://github.com/login/device",
+			"expires_in":       900,
+			"interval":         5,
+		})
+
+	c := Config{
+		ClientID:      "5163c01dea",
+		Scope:         []string{"repo", "user"},
+		DeviceAuthURL: "https://github.com/login/device/code",
+	}
+	code, err := c.DeviceCode(context.Background())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := code.DeviceCode, "3584d83530"; got != want {
+		t.Errorf("Want device_code %s, got %s", want, got)
+	}
+	if got, want := code.UserCode, "WDJB-MJHT"; got != want {
+		t.Errorf("Want user_code %s, got %s", want, got)
+	}
+	if got, want := code.VerificationURI, "https://github.com/login/device"; got != want {
+		t.Errorf("Want verification_uri %s, got %s", want, got)
+	}
+	if got, want := code.Interval, 5*time.Second; got != want {
+		t.Errorf("Want interval %s, got %s", want, got)
+	}
+	if code.Expires.IsZero() {
+		t.Errorf("Want device code expiry")
+	}
+}
+
+func TestDeviceCodeUnsupported(t *testing.T) {
+	c := Config{}
+	_, err := c.DeviceCode(context.Background())
+	if err != login.ErrUnsupported {
+		t.Errorf("Want ErrUnsupported, got %v", err)
+	}
+}
+
+func TestDeviceToken(t *testing.T) {
+	defer gock.Off()
+
+	var waits []time.Duration
+	after = func(d time.Duration) <-chan time.Time {
+		waits = append(waits, d)
+		return time.After(0)
+	}
+	defer func() {
+		after = time.After
+	}()
+
+	gock.New("https://github.com").
+		Post("/login/oauth/access_token").
+		SetMatcher(gock.NewMatcher()).
+		Reply(200).
+		JSON(&Error{Code: "authorization_pending"})
+
+	gock.New("https://github.com").
+		Post("/login/oauth/access_token").
+		SetMatcher(gock.NewMatcher()).
+		Reply(200).
+		JSON(&Error{Code: "slow_down"})
+
+	gock.New("https://github.com").
+		Post("/login/oauth/access_token").
+		SetMatcher(gock.NewMatcher()).
+		BodyString("device_code=3584d83530").
+		Reply(200).
+		JSON(&token{
+			AccessToken: "755bb80e5b",
+			TokenType:   "bearer",
+		})
+
+	c := Config{
+		ClientID:       "5163c01dea",
+		AccessTokenURL: "https://github.com/login/oauth/access_token",
+	}
+	code := &login.DeviceCode{
+		DeviceCode: "3584d83530",
+		Interval:   time.Second,
+	}
+	token, err := c.DeviceToken(context.Background(), code)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := token.Access, "755bb80e5b"; got != want {
+		t.Errorf("Want access_token %s, got %s", want, got)
+	}
+	want := []time.Duration{time.Second, time.Second, 6 * time.Second}
+	if len(waits) != len(want) {
+		t.Errorf("Want %d polling requests, got %d", len(want), len(waits))
+		return
+	}
+	for i := range want {
+		if waits[i] != want[i] {
+			t.Errorf("Want interval %s, got %s", want[i], waits[i])
+		}
+	}
+}
+
+func TestDeviceTokenDenied(t *testing.T) {
+	defer gock.Off()
+
+	after = func(time.Duration) <-chan time.Time {
+		return time.After(0)
+	}
+	defer func() {
+		after = time.After
+	}()
+
+	gock.New("https://github.com").
+		Post("/login/oauth/access_token").
+		SetMatcher(gock.NewMatcher()).
+		Reply(200).
+		JSON(&Error{Code: "access_denied"})
+
+	c := Config{
+		ClientID:       "5163c01dea",
+		AccessTokenURL: "https://github.com/login/oauth/access_token",
+	}
+	_, err := c.DeviceToken(context.Background(), &login.DeviceCode{DeviceCode: "3584d83530"})
+	if !errors.Is(err, login.ErrAccessDenied) {
+		t.Errorf("Want ErrAccessDenied, got %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2017 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package oauth2
+
+import (
+	"errors"
+	"net/url"
+
+	"github.com/drone/go-login/login"
+)
+
+// ErrState indicates the state is invalid.
+var ErrState = login.ErrState
+
+// ErrRefresh indicates the token cannot be refreshed
+// because it has no refresh token.
+var ErrRefresh = errors.New("Missing refresh token")
+
+// Error represents a failed authorization request.
+type Error struct {
+	Code string `json:"error"`
+	Desc string `json:"error_description"`
+	URI  string `json:"error_uri"`
+}
+
+// Error returns the string representation of an
+// authorization error.
+func (e *Error) Error() string {
+	return e.Code + ": " + e.Desc
+}
+
+// authorizationError converts the authorization error
+// returned in the authorization callback to a login error.
+func authorizationError(e *Error) *login.Error {
+	kind := login.ErrAuthorization
+	if e.Code == "access_denied" {
+		kind = login.ErrAccessDenied
+	}
+	return &login.Error{
+		Kind:        kind,
+		Code:        e.Code,
+		Description: e.Desc,
+		URI:         e.URI,
+		Err:         e,
+	}
+}
+
+// stateError converts the error returned by the state
+// store to a login error.
+func stateError(err error) *login.Error {
+	if err == ErrState {
+		return &login.Error{Kind: login.ErrState}
+	}
+	return &login.Error{Kind: login.ErrState, Err: err}
+}
+
+// revocationError converts the error returned by the
+// revocation endpoint to a login error.
+func revocationError(err error) *login.Error {
+	e := exchangeError(err)
+	if e.Kind == login.ErrTokenExchange {
+		e.Kind = login.ErrRevocation
+	}
+	return e
+}
+
+// introspectionError converts the error returned by the
+// introspection endpoint to a login error.
+func introspectionError(err error) *login.Error {
+	e := exchangeError(err)
+	if e.Kind == login.ErrTokenExchange {
+		e.Kind = login.ErrIntrospection
+	}
+	return e
+}
+
+// deviceError converts the error returned by the device
+// authorization endpoint to a login error.
+func deviceError(err error) *login.Error {
+	e := exchangeError(err)
+	if e.Kind == login.ErrTokenExchange {
+		e.Kind = login.ErrAuthorization
+	}
+	return e
+}
+
+// exchangeError converts the error returned by the token
+// endpoint to a login error.
+func exchangeError(err error) *login.Error {
+	switch e := err.(type) {
+	case *login.Error:
+		return e
+	case *Error:
+		return &login.Error{
+			Kind:        login.ErrTokenExchange,
+			Code:        e.Code,
+			Description: e.Desc,
+			URI:         e.URI,
+			Err:         e,
+		}
+	case *url.Error:
+		return &login.Error{
+			Kind: login.ErrUnreachable,
+			Err:  e,
+		}
+	default:
+		return &login.Error{
+			Kind: login.ErrTokenExchange,
+			Err:  err,
+		}
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright 2017 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package oauth2
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/drone/go-login/login"
+)
+
+func TestError(t *testing.T) {
+	err := Error{}
+	err.Code = "invalid_request"
+	err.Desc = " The request is missing a required parameter"
+	if got, want := err.Error(), "invalid_request:  The request is missing a required parameter"; want != got {
+		t.Errorf("Want error message %q, got %q", want, got)
+	}
+}
+
+func TestExchangeError(t *testing.T) {
+	tests := []struct {
+		err  error
+		kind error
+	}{
+		{&Error{Code: "invalid_grant"}, login.ErrTokenExchange},
+		{&url.Error{Op: "Post", Err: errors.New("connection refused")}, login.ErrUnreachable},
+		{errors.New("unexpected EOF"), login.ErrTokenExchange},
+	}
+	for _, test := range tests {
+		err := exchangeError(test.err)
+		if !errors.Is(err, test.kind) {
+			t.Errorf("Want error %v, got %v", test.kind, err)
+		}
+		if errors.Unwrap(err) != test.err {
+			t.Errorf("Want error wraps the cause")
+		}
+	}
+}
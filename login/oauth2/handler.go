@@ -0,0 +1,201 @@
+// Copyright 2017 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package oauth2
+
+import (
+	"net/http"
+
+	"github.com/drone/go-login/login"
+	"github.com/drone/go-login/login/internal/api"
+	"github.com/drone/go-login/login/logger"
+)
+
+// Handler returns a Handler that runs h at the completion
+// of the oauth2 authorization flow.
+func Handler(h http.Handler, c *Config) http.Handler {
+	return &handler{next: h, conf: c, logs: c.Logger}
+}
+
+type handler struct {
+	conf *Config
+	next http.Handler
+	logs logger.Logger
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// attaches the server address to the context, which
+	// identifies the server that issued the token when
+	// running handlers for multiple servers.
+	if len(h.conf.Server) != 0 {
+		ctx = login.WithServer(ctx, h.conf.Server)
+	}
+
+	// checks for the error query parameter in the request.
+	// If non-empty, write to the context and proceed with
+	// the next http.Handler in the chain.
+	if erro := r.FormValue("error"); erro != "" {
+		h.logger().Errorf("oauth: authorization error: %s", erro)
+		ctx = login.WithError(ctx, authorizationError(&Error{
+			Code: erro,
+			Desc: r.FormValue("error_description"),
+			URI:  r.FormValue("error_uri"),
+		}))
+		h.next.ServeHTTP(w, r.WithContext(ctx))
+		return
+	}
+
+	// checks for the code query parameter in the request
+	// If empty, redirect to the authorization endpoint.
+	code := r.FormValue("code")
+	if len(code) == 0 {
+		value, err := random()
+		if err != nil {
+			h.logger().Errorf("oauth: cannot create state: %s", err)
+			ctx = login.WithError(ctx, err)
+			h.next.ServeHTTP(w, r.WithContext(ctx))
+			return
+		}
+		state := &login.State{
+			Value:    value,
+			ReturnTo: returnTo(r),
+		}
+		upgrade := login.UpgradeFrom(ctx)
+		if upgrade != nil {
+			state.Upgrade = true
+			state.Scopes = upgrade.Scopes
+		}
+		if h.conf.PKCE {
+			verifier, err := createVerifier()
+			if err != nil {
+				h.logger().Errorf("oauth: cannot create code verifier: %s", err)
+				ctx = login.WithError(ctx, err)
+				h.next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+			state.Verifier = verifier
+		}
+		if h.conf.IDTokenVerifier != nil {
+			nonce, err := random()
+			if err != nil {
+				h.logger().Errorf("oauth: cannot create nonce: %s", err)
+				ctx = login.WithError(ctx, err)
+				h.next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+			state.Nonce = nonce
+		}
+		if err := h.conf.stateStore().Create(w, r, state); err != nil {
+			h.logger().Errorf("oauth: cannot persist state: %s", err)
+			ctx = login.WithError(ctx, err)
+			h.next.ServeHTTP(w, r.WithContext(ctx))
+			return
+		}
+		http.Redirect(w, r, h.conf.authorizeRedirect(state, upgrade, r.URL.Query()), 303)
+		return
+	}
+
+	// checks for the state query parameter in the requet.
+	// If empty, write the error to the context and proceed
+	// with the next http.Handler in the chain. The persisted
+	// state includes the code verifier when the PKCE
+	// extension is enabled.
+	store := h.conf.stateStore()
+	state, err := store.Validate(r, r.FormValue("state"))
+	store.Delete(w, r)
+	if err == nil && h.conf.PKCE && len(state.Verifier) == 0 {
+		err = ErrState
+	}
+	if err != nil {
+		h.logger().Errorln("oauth: invalid or missing state")
+		ctx = login.WithError(ctx, stateError(err))
+		h.next.ServeHTTP(w, r.WithContext(ctx))
+		return
+	}
+
+	// attaches the url the user requested before the
+	// authorization flow started to the context.
+	if len(state.ReturnTo) != 0 {
+		ctx = login.WithReturnTo(ctx, state.ReturnTo)
+	}
+
+	// attaches the upgrade to the context, which indicates
+	// the token is issued for additional scopes for a user
+	// that is already logged in.
+	if state.Upgrade {
+		ctx = login.WithUpgrade(ctx, &login.Upgrade{Scopes: state.Scopes})
+	}
+
+	// requests the access_token and refresh_token from the
+	// authorization server. If an error is encountered,
+	// write the error to the context and prceed with the
+	// next http.Handler in the chain.
+	source, err := h.conf.exchange(ctx, code, state.Value, state.Verifier)
+	if err != nil {
+		h.logger().Errorf("oauth: cannot exchange code: %s: %s", code, err)
+		ctx = login.WithError(ctx, exchangeError(err))
+		h.next.ServeHTTP(w, r.WithContext(ctx))
+		return
+	}
+
+	// verifies the OpenID Connect id_token and attaches the
+	// id_token claims to the context. If invalid, write the
+	// error to the context and proceed with the next
+	// http.Handler in the chain.
+	if h.conf.IDTokenVerifier != nil {
+		verified, err := h.conf.IDTokenVerifier.Verify(ctx, source.IDToken, state.Nonce)
+		if err != nil {
+			h.logger().Errorf("oauth: invalid id_token: %s", err)
+			ctx = login.WithError(ctx, api.Wrap(login.ErrIDToken, err))
+			h.next.ServeHTTP(w, r.WithContext(ctx))
+			return
+		}
+		ctx = verified
+	}
+
+	// converts the oauth2 token type to the internal Token
+	// type.
+	token := source.convert()
+
+	// retrieves the authenticated user from the provider
+	// and attaches to the context. If an error is
+	// encountered, write the error to the context and
+	// proceed with the next http.Handler in the chain.
+	if h.conf.Identifier != nil {
+		user, err := h.conf.Identifier.Identify(ctx, token)
+		if err != nil {
+			h.logger().Errorf("oauth: cannot identify user: %s", err)
+			ctx = login.WithError(ctx, api.Wrap(login.ErrIdentity, err))
+			h.next.ServeHTTP(w, r.WithContext(ctx))
+			return
+		}
+		ctx = login.WithUser(ctx, user)
+	}
+
+	// authorizes the user. If the user is not authorized,
+	// write the error to the context and proceed with the
+	// next http.Handler in the chain.
+	if h.conf.Authorizer != nil {
+		if err := h.conf.Authorizer.Authorize(ctx, token); err != nil {
+			h.logger().Errorf("oauth: user is not authorized: %s", err)
+			ctx = login.WithError(ctx, api.Wrap(login.ErrNotAuthorized, err))
+			h.next.ServeHTTP(w, r.WithContext(ctx))
+			return
+		}
+	}
+
+	// attaches the token to the context.
+	ctx = login.WithToken(ctx, token)
+
+	h.next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+func (h *handler) logger() logger.Logger {
+	if h.logs == nil {
+		return logger.Discard()
+	}
+	return h.logs
+}
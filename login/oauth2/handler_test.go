@@ -0,0 +1,276 @@
+// Copyright 2017 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package oauth2
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/drone/go-login/login"
+	"github.com/h2non/gock"
+)
+
+func TestHandler(t *testing.T) {
+	defer gock.Off()
+
+	var verifier string
+	gock.New("https://gitlab.com").
+		Post("/oauth/token").
+		SetMatcher(gock.NewMatcher()).
+		AddMatcher(func(r *http.Request, _ *gock.Request) (bool, error) {
+			switch {
+			case r.FormValue("code") != "3da5415599":
+				return false, errors.New("Unexpected code")
+			case r.FormValue("code_verifier") == "":
+				return false, errors.New("Missing code_verifier")
+			default:
+				verifier = r.FormValue("code_verifier")
+				return true, nil
+			}
+		}).
+		Reply(200).
+		JSON(&token{
+			AccessToken: "755bb80e5b",
+		})
+
+	var ctx context.Context
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		ctx = r.Context()
+	}
+	h := Handler(http.HandlerFunc(fn), &Config{
+		ClientID:         "5163c01dea",
+		ClientSecret:     "14c71a2a21",
+		AccessTokenURL:   "https://gitlab.com/oauth/token",
+		AuthorizationURL: "https://gitlab.com/oauth/authorize",
+		PKCE:             true,
+	})
+
+	// the first request redirects to the authorization
+	// endpoint and persists the state.
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/login?return_to=%2Foctocat%2Fhello-world", nil)
+	h.ServeHTTP(w, r)
+
+	if got, want := w.Code, 303; got != want {
+		t.Errorf("Want status code %d, got %d", want, got)
+	}
+	location, _ := url.Parse(w.Header().Get("Location"))
+	state := location.Query().Get("state")
+	if state == "" {
+		t.Errorf("Want state in authorization redirect")
+	}
+	if location.Query().Get("code_challenge") == "" {
+		t.Errorf("Want code challenge in authorization redirect")
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) == 0 {
+		t.Errorf("Want state cookie")
+		return
+	}
+
+	// the callback request validates the state and
+	// exchanges the code for a token.
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("GET", "/login?code=3da5415599&state="+state, nil)
+	r.AddCookie(cookies[0])
+	h.ServeHTTP(w, r)
+
+	if err := login.ErrorFrom(ctx); err != nil {
+		t.Errorf("Want no error, got %s", err)
+		return
+	}
+	if got, want := login.TokenFrom(ctx).Access, "755bb80e5b"; got != want {
+		t.Errorf("Want access token %s, got %s", want, got)
+	}
+	if got, want := login.ReturnToFrom(ctx), "/octocat/hello-world"; got != want {
+		t.Errorf("Want return url %s, got %s", want, got)
+	}
+	if got, want := challenge(verifier), location.Query().Get("code_challenge"); got != want {
+		t.Errorf("Want code verifier matching the code challenge")
+	}
+}
+
+func TestHandlerInvalidState(t *testing.T) {
+	var ctx context.Context
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		ctx = r.Context()
+	}
+	h := Handler(http.HandlerFunc(fn), &Config{
+		AuthorizationURL: "https://gitlab.com/oauth/authorize",
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/login?code=3da5415599&state=9f41a95cba5", nil)
+	h.ServeHTTP(w, r)
+
+	if err := login.ErrorFrom(ctx); !errors.Is(err, login.ErrState) {
+		t.Errorf("Want error %v, got %v", login.ErrState, err)
+	}
+}
+
+func TestHandlerAccessDenied(t *testing.T) {
+	var ctx context.Context
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		ctx = r.Context()
+	}
+	h := Handler(http.HandlerFunc(fn), &Config{
+		AuthorizationURL: "https://gitlab.com/oauth/authorize",
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/login?error=access_denied&error_description=The+user+denied+access", nil)
+	h.ServeHTTP(w, r)
+
+	err := login.ErrorFrom(ctx)
+	if !errors.Is(err, login.ErrAccessDenied) {
+		t.Errorf("Want error %v, got %v", login.ErrAccessDenied, err)
+	}
+	var e *login.Error
+	if !errors.As(err, &e) {
+		t.Errorf("Want login error")
+		return
+	}
+	if got, want := e.Description, "The user denied access"; got != want {
+		t.Errorf("Want error description %q, got %q", want, got)
+	}
+}
+
+type mockIdentifier struct {
+	user *login.User
+	err  error
+}
+
+func (m *mockIdentifier) Identify(ctx context.Context, token *login.Token) (*login.User, error) {
+	return m.user, m.err
+}
+
+func TestHandlerIdentify(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://gitlab.com").
+		Post("/oauth/token").
+		SetMatcher(gock.NewMatcher()).
+		Times(2).
+		Reply(200).
+		JSON(&token{
+			AccessToken: "755bb80e5b",
+		})
+
+	tests := []struct {
+		identifier *mockIdentifier
+		err        error
+	}{
+		{
+			identifier: &mockIdentifier{user: &login.User{Login: "janedoe"}},
+		},
+		{
+			identifier: &mockIdentifier{err: errors.New("Unauthorized")},
+			err:        login.ErrIdentity,
+		},
+	}
+
+	for _, test := range tests {
+		var ctx context.Context
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			ctx = r.Context()
+		}
+		store := new(login.MemoryStore)
+		h := Handler(http.HandlerFunc(fn), &Config{
+			AccessTokenURL:   "https://gitlab.com/oauth/token",
+			AuthorizationURL: "https://gitlab.com/oauth/authorize",
+			Identifier:       test.identifier,
+			StateStore:       store,
+		})
+
+		w := httptest.NewRecorder()
+		store.Create(w, httptest.NewRequest("GET", "/", nil), &login.State{Value: "9f41a95cba5"})
+		r := httptest.NewRequest("GET", "/login?code=3da5415599&state=9f41a95cba5", nil)
+		r.AddCookie(w.Result().Cookies()[0])
+		h.ServeHTTP(httptest.NewRecorder(), r)
+
+		if test.err != nil {
+			if err := login.ErrorFrom(ctx); !errors.Is(err, test.err) {
+				t.Errorf("Want error %v, got %v", test.err, err)
+			}
+			if login.TokenFrom(ctx) != nil {
+				t.Errorf("Want nil token on error")
+			}
+			continue
+		}
+		if got, want := login.UserFrom(ctx), test.identifier.user; got != want {
+			t.Errorf("Want user %v, got %v", want, got)
+		}
+		if login.TokenFrom(ctx) == nil {
+			t.Errorf("Want token in context")
+		}
+	}
+}
+
+func TestHandlerUpgrade(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://gitlab.com").
+		Post("/oauth/token").
+		SetMatcher(gock.NewMatcher()).
+		Reply(200).
+		JSON(map[string]string{
+			"access_token": "9b2b4b1c7f",
+			"scope":        "read_user api",
+		})
+
+	var ctx context.Context
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		ctx = r.Context()
+	}
+	h := Handler(http.HandlerFunc(fn), &Config{
+		ClientID:         "5163c01dea",
+		AccessTokenURL:   "https://gitlab.com/oauth/token",
+		AuthorizationURL: "https://gitlab.com/oauth/authorize",
+		Scope:            []string{"read_user"},
+	})
+
+	// the application requests additional scopes for the
+	// logged in user.
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/login", nil)
+	r = r.WithContext(login.WithUpgrade(r.Context(), &login.Upgrade{
+		Scopes: []string{"api"},
+	}))
+	h.ServeHTTP(w, r)
+
+	location, _ := url.Parse(w.Header().Get("Location"))
+	if got, want := location.Query().Get("scope"), "read_user api"; got != want {
+		t.Errorf("Want scope %q, got %q", want, got)
+	}
+
+	// the callback is a regular request, and the upgrade
+	// is restored from the state.
+	w2 := httptest.NewRecorder()
+	r = httptest.NewRequest("GET", "/login?code=3da5415599&state="+location.Query().Get("state"), nil)
+	for _, c := range w.Result().Cookies() {
+		r.AddCookie(c)
+	}
+	h.ServeHTTP(w2, r)
+
+	if err := login.ErrorFrom(ctx); err != nil {
+		t.Errorf("Want no error, got %s", err)
+		return
+	}
+	upgrade := login.UpgradeFrom(ctx)
+	if upgrade == nil {
+		t.Errorf("Want token tagged as an upgrade")
+		return
+	}
+	if got, want := upgrade.Scopes, []string{"api"}; len(got) != 1 || got[0] != want[0] {
+		t.Errorf("Want upgrade scopes %v, got %v", want, got)
+	}
+	if err := login.CheckScopes(login.TokenFrom(ctx), []string{"read_user", "api"}); err != nil {
+		t.Errorf("Want upgraded scopes granted, got %s", err)
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2017 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package oauth2
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// createVerifier generates and returns a new PKCE code
+// verifier.
+func createVerifier() (string, error) {
+	return random()
+}
+
+// challenge returns the S256 code challenge derived from
+// the PKCE code verifier.
+func challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// random creates an opaque value shared between the
+// http.Request and the callback used to validate redirects.
+// The value is generated from 32 bytes of cryptographically
+// secure random data.
+func random() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// returnTo returns the url the user requested before the
+// authorization flow started, from the return_to or next
+// query parameter. Only same-origin urls are returned to
+// prevent open redirects; absolute urls are converted to
+// paths.
+func returnTo(r *http.Request) string {
+	target := r.FormValue("return_to")
+	if target == "" {
+		target = r.FormValue("next")
+	}
+	// browsers treat protocol-relative urls and urls with
+	// backslashes as absolute urls to another host.
+	if strings.HasPrefix(target, "//") || strings.Contains(target, "\\") {
+		return ""
+	}
+	u, err := url.Parse(target)
+	if err != nil || u.User != nil || u.Opaque != "" {
+		return ""
+	}
+	if u.IsAbs() || u.Host != "" {
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return ""
+		}
+		if !strings.EqualFold(u.Host, r.Host) {
+			return ""
+		}
+	} else if !strings.HasPrefix(u.Path, "/") {
+		return ""
+	}
+	path := u.RequestURI()
+	if strings.HasPrefix(path, "//") {
+		return ""
+	}
+	return path
+}
+
+// merge returns the scopes followed by the additional
+// scopes that are not already included.
+func merge(scopes, additional []string) []string {
+	out := append([]string{}, scopes...)
+	for _, a := range additional {
+		found := false
+		for _, s := range out {
+			if s == a {
+				found = true
+				break
+			}
+		}
+		if !found {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// namespace returns the state namespace for the server
+// address, which is safe to use in a cookie name.
+func namespace(server string) string {
+	if u, err := url.Parse(server); err == nil && len(u.Host) != 0 {
+		server = u.Host + u.Path
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, strings.TrimSuffix(server, "/"))
+}
@@ -0,0 +1,94 @@
+// Copyright 2017 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package oauth2
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func Test_createVerifier(t *testing.T) {
+	v, err := createVerifier()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	// 32 random bytes encode to 43 base64url characters,
+	// the minimum verifier length permitted by RFC 7636.
+	if got, want := len(v), 43; got != want {
+		t.Errorf("Want verifier length %d, got %d", want, got)
+	}
+}
+
+func Test_challenge(t *testing.T) {
+	// example from RFC 7636 Appendix B.
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	if got, want := challenge(verifier), "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"; got != want {
+		t.Errorf("Want code challenge %s, got %s", want, got)
+	}
+}
+
+func Test_random(t *testing.T) {
+	a, err := random()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	b, _ := random()
+	if a == b {
+		t.Errorf("Want unique random values")
+	}
+	if got, want := len(a), 43; got != want {
+		t.Errorf("Want random value length %d, got %d", want, got)
+	}
+}
+
+func Test_returnTo(t *testing.T) {
+	tests := []struct {
+		param string
+		value string
+		want  string
+	}{
+		{"return_to", "/octocat/hello-world", "/octocat/hello-world"},
+		{"return_to", "/octocat/hello-world?tab=builds#top", "/octocat/hello-world?tab=builds"},
+		{"next", "/octocat/hello-world", "/octocat/hello-world"},
+		{"next", "https://example.com/octocat", "/octocat"},
+		{"next", "http://EXAMPLE.com/octocat", "/octocat"},
+		{"next", "https://example.com//evil.com", ""},
+		{"next", "https://evil.com/octocat", ""},
+		{"next", "https://example.com.evil.com/", ""},
+		{"next", "https://user@example.com/", ""},
+		{"next", "//evil.com/octocat", ""},
+		{"next", "/\\evil.com/octocat", ""},
+		{"next", "https:evil.com", ""},
+		{"next", "javascript:alert(1)", ""},
+		{"next", "octocat/hello-world", ""},
+		{"next", "", ""},
+	}
+	for _, test := range tests {
+		q := url.Values{test.param: {test.value}}
+		r := httptest.NewRequest("GET", "/login?"+q.Encode(), nil)
+		if got := returnTo(r); got != test.want {
+			t.Errorf("Want return url %q for %q, got %q", test.want, test.value, got)
+		}
+	}
+}
+
+func Test_namespace(t *testing.T) {
+	tests := []struct {
+		server string
+		want   string
+	}{
+		{"https://github.com", "github_com"},
+		{"https://github.example.com:8443/", "github_example_com_8443"},
+		{"https://example.com/gitlab", "example_com_gitlab"},
+	}
+	for _, test := range tests {
+		if got := namespace(test.server); got != test.want {
+			t.Errorf("Want namespace %q for %q, got %q", test.want, test.server, got)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2017 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package oidc
+
+import (
+	"context"
+	"time"
+
+	"github.com/drone/go-login/login"
+	"github.com/drone/go-login/login/internal/api"
+)
+
+type key int
+
+const claimsKey key = iota
+
+// Claims represents the claims of a verified id_token.
+type Claims struct {
+	Issuer            string
+	Subject           string
+	Audience          []string
+	AuthorizedParty   string
+	Expires           time.Time
+	IssuedAt          time.Time
+	Nonce             string
+	Name              string
+	PreferredUsername string
+	Email             string
+	EmailVerified     bool
+	Picture           string
+
+	// Raw contains all claims, including claims not
+	// defined by the OpenID Connect specification.
+	Raw map[string]interface{}
+}
+
+// WithClaims returns a parent context with the id_token
+// claims.
+func WithClaims(parent context.Context, claims *Claims) context.Context {
+	return context.WithValue(parent, claimsKey, claims)
+}
+
+// ClaimsFrom returns the id_token claims from the context.
+func ClaimsFrom(ctx context.Context) *Claims {
+	claims, _ := ctx.Value(claimsKey).(*Claims)
+	return claims
+}
+
+// user returns the user identified by the claims.
+func (c *Claims) user() *login.User {
+	user := &login.User{
+		ID:     c.Subject,
+		Login:  c.PreferredUsername,
+		Name:   c.Name,
+		Email:  c.Email,
+		Avatar: c.Picture,
+		Host:   api.Host(c.Issuer),
+	}
+	if user.Login == "" {
+		user.Login = c.Subject
+	}
+	return user
+}
+
+// parseClaims converts the decoded json claims.
+func parseClaims(raw map[string]interface{}) (*Claims, error) {
+	if _, ok := raw["sub"].(string); !ok {
+		return nil, errMalformed
+	}
+	claims := &Claims{
+		Issuer:            stringClaim(raw, "iss"),
+		Subject:           stringClaim(raw, "sub"),
+		AuthorizedParty:   stringClaim(raw, "azp"),
+		Expires:           timeClaim(raw, "exp"),
+		IssuedAt:          timeClaim(raw, "iat"),
+		Nonce:             stringClaim(raw, "nonce"),
+		Name:              stringClaim(raw, "name"),
+		PreferredUsername: stringClaim(raw, "preferred_username"),
+		Email:             stringClaim(raw, "email"),
+		Picture:           stringClaim(raw, "picture"),
+		Raw:               raw,
+	}
+	// the audience is either a single string or an
+	// array of strings.
+	switch v := raw["aud"].(type) {
+	case string:
+		claims.Audience = []string{v}
+	case []interface{}:
+		for _, aud := range v {
+			if s, ok := aud.(string); ok {
+				claims.Audience = append(claims.Audience, s)
+			}
+		}
+	}
+	// some providers encode the email_verified claim
+	// as a string.
+	switch v := raw["email_verified"].(type) {
+	case bool:
+		claims.EmailVerified = v
+	case string:
+		claims.EmailVerified = v == "true"
+	}
+	return claims, nil
+}
+
+func stringClaim(raw map[string]interface{}, name string) string {
+	s, _ := raw[name].(string)
+	return s
+}
+
+func timeClaim(raw map[string]interface{}, name string) time.Time {
+	f, ok := raw[name].(float64)
+	if !ok {
+		return time.Time{}
+	}
+	return time.Unix(int64(f), 0)
+}
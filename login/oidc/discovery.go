@@ -0,0 +1,74 @@
+// Copyright 2017 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package oidc
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// errIssuer indicates the issuer in the discovery document
+// does not match the configured issuer.
+var errIssuer = errors.New("oidc: issuer does not match the configured issuer")
+
+// provider stores the OpenID Provider metadata returned by
+// the discovery endpoint.
+type provider struct {
+	Issuer                   string   `json:"issuer"`
+	AuthorizationEndpoint    string   `json:"authorization_endpoint"`
+	TokenEndpoint            string   `json:"token_endpoint"`
+	UserinfoEndpoint         string   `json:"userinfo_endpoint"`
+	JWKSURI                  string   `json:"jwks_uri"`
+	RevocationEndpoint       string   `json:"revocation_endpoint"`
+	IntrospectionEndpoint    string   `json:"introspection_endpoint"`
+	CodeChallengeMethods     []string `json:"code_challenge_methods_supported"`
+	TokenEndpointAuthMethods []string `json:"token_endpoint_auth_methods_supported"`
+}
+
+// supportsPKCE reports whether the provider supports the
+// S256 PKCE code challenge method.
+func (p *provider) supportsPKCE() bool {
+	return contains(p.CodeChallengeMethods, "S256")
+}
+
+// supportsBasicAuth reports whether the provider supports
+// client authentication using the authorization header,
+// which is the default if no methods are advertised.
+func (p *provider) supportsBasicAuth() bool {
+	return len(p.TokenEndpointAuthMethods) == 0 ||
+		contains(p.TokenEndpointAuthMethods, "client_secret_basic") ||
+		!contains(p.TokenEndpointAuthMethods, "client_secret_post")
+}
+
+// discover returns the provider metadata, retrieving the
+// discovery document on first use.
+func (c *Config) discover(ctx context.Context) (*provider, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.provider != nil {
+		return c.provider, nil
+	}
+	issuer := strings.TrimSuffix(c.Issuer, "/")
+	out := new(provider)
+	err := c.api().Get(ctx, issuer+"/.well-known/openid-configuration", out)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSuffix(out.Issuer, "/") != issuer {
+		return nil, errIssuer
+	}
+	c.provider = out
+	return out, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,172 @@
+// Copyright 2017 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"math/big"
+	"strings"
+)
+
+// errKey indicates no key in the json web key set matches
+// the id_token header.
+var errKey = errors.New("oidc: no matching signing key")
+
+// publicKey is a parsed json web key.
+type publicKey struct {
+	id  string
+	alg string
+	key crypto.PublicKey
+}
+
+// jsonWebKey is a json web key as defined by RFC 7517.
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// key returns the public key used to sign a token with the
+// given key id and algorithm. The key set is retrieved
+// again if no cached key matches, in case the provider
+// rotated its keys.
+func (c *Config) key(ctx context.Context, p *provider, kid, alg string) (crypto.PublicKey, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if key := match(c.keys, kid, alg); key != nil {
+		return key, nil
+	}
+	keys, err := c.fetchKeys(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	c.keys = keys
+	if key := match(c.keys, kid, alg); key != nil {
+		return key, nil
+	}
+	return nil, errKey
+}
+
+// fetchKeys retrieves and parses the json web key set.
+// Keys that are not used for signatures or that cannot
+// be parsed are skipped.
+func (c *Config) fetchKeys(ctx context.Context, p *provider) ([]*publicKey, error) {
+	out := struct {
+		Keys []*jsonWebKey `json:"keys"`
+	}{}
+	if err := c.api().Get(ctx, p.JWKSURI, &out); err != nil {
+		return nil, err
+	}
+	var keys []*publicKey
+	for _, jwk := range out.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.parse()
+		if err != nil {
+			continue
+		}
+		keys = append(keys, &publicKey{
+			id:  jwk.Kid,
+			alg: jwk.Alg,
+			key: key,
+		})
+	}
+	return keys, nil
+}
+
+// match returns the key matching the key id and algorithm.
+// If the token does not specify a key id, the first key
+// of the matching type is returned.
+func match(keys []*publicKey, kid, alg string) crypto.PublicKey {
+	for _, k := range keys {
+		if kid != "" && k.id != kid {
+			continue
+		}
+		if k.alg != "" && k.alg != alg {
+			continue
+		}
+		switch k.key.(type) {
+		case *rsa.PublicKey:
+			if strings.HasPrefix(alg, "RS") || strings.HasPrefix(alg, "PS") {
+				return k.key
+			}
+		case *ecdsa.PublicKey:
+			if strings.HasPrefix(alg, "ES") {
+				return k.key
+			}
+		}
+	}
+	return nil
+}
+
+// parse returns the public key for the json web key.
+func (k *jsonWebKey) parse() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+			return nil, errors.New("oidc: invalid rsa exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, errors.New("oidc: unsupported curve")
+		}
+		x, err := decodeInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("oidc: invalid ecdsa key")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, errors.New("oidc: unsupported key type")
+	}
+}
+
+// decodeInt decodes a base64url encoded big-endian integer.
+func decodeInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, errors.New("oidc: empty key parameter")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,230 @@
+// Copyright 2017 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package oidc provides a generic OpenID Connect login
+// provider. The provider endpoints are discovered from the
+// issuer's /.well-known/openid-configuration document and
+// the id_token signature is verified against the issuer's
+// json web key set.
+package oidc
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/drone/go-login/login"
+	"github.com/drone/go-login/login/internal/api"
+	"github.com/drone/go-login/login/logger"
+	"github.com/drone/go-login/login/oauth2"
+)
+
+var (
+	_ login.Middleware   = (*Config)(nil)
+	_ login.Refresher    = (*Config)(nil)
+	_ login.Identifier   = (*Config)(nil)
+	_ login.Revoker      = (*Config)(nil)
+	_ login.Introspector = (*Config)(nil)
+)
+
+// default scopes requested if no scopes are configured.
+var defaultScope = []string{"openid", "profile", "email"}
+
+// Config configures an OpenID Connect authorization
+// provider.
+type Config struct {
+	Client       *http.Client
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Issuer       string
+	Scope        []string
+	StateStore   login.StateStore
+	Logger       logger.Logger
+	Dumper       logger.Dumper
+
+	// Prompt is the prompt parameter, such as consent or
+	// login, sent with the authorization request.
+	Prompt string
+
+	// LoginHint suggests the account used to sign in.
+	LoginHint string
+
+	// AuthParams are additional authorization parameters.
+	AuthParams map[string]string
+
+	// RequestParams are the login request query parameters
+	// copied to the authorization request, such as
+	// login_hint.
+	RequestParams []string
+
+	mu       sync.Mutex
+	provider *provider
+	keys     []*publicKey
+}
+
+// Handler returns a http.Handler that runs h at the
+// completion of the OpenID Connect authorization flow. The
+// authorization details, the authenticated user and the
+// id_token claims are available to h in the http.Request
+// context.
+func (c *Config) Handler(h http.Handler) http.Handler {
+	return &handler{conf: c, next: h}
+}
+
+// Refresh refreshes the OpenID Connect authorization
+// token.
+func (c *Config) Refresh(ctx context.Context, token *login.Token) (*login.Token, error) {
+	p, err := c.discover(ctx)
+	if err != nil {
+		return nil, api.Wrap(login.ErrUnreachable, err)
+	}
+	return c.config(p).Refresh(ctx, token)
+}
+
+// Revoke revokes the OpenID Connect authorization token.
+// ErrUnsupported is returned if the provider does not
+// advertise a revocation endpoint.
+func (c *Config) Revoke(ctx context.Context, token *login.Token) error {
+	p, err := c.discover(ctx)
+	if err != nil {
+		return api.Wrap(login.ErrUnreachable, err)
+	}
+	return c.config(p).Revoke(ctx, token)
+}
+
+// Identify returns the user that authorized the token. If
+// the context includes the id_token claims the user is
+// derived from the claims, else the user is retrieved
+// from the userinfo endpoint.
+func (c *Config) Identify(ctx context.Context, token *login.Token) (*login.User, error) {
+	if claims := ClaimsFrom(ctx); claims != nil {
+		return claims.user(), nil
+	}
+	if token == nil {
+		return nil, login.ErrToken
+	}
+	p, err := c.discover(ctx)
+	if err != nil {
+		return nil, api.Wrap(login.ErrIdentity, err)
+	}
+	raw := map[string]interface{}{}
+	client := c.api()
+	client.Authorize = api.Bearer(token.Access)
+	if err := client.Get(ctx, p.UserinfoEndpoint, &raw); err != nil {
+		return nil, api.Wrap(login.ErrIdentity, err)
+	}
+	claims, err := parseClaims(raw)
+	if err != nil {
+		return nil, api.Wrap(login.ErrIdentity, err)
+	}
+	claims.Issuer = p.Issuer
+	return claims.user(), nil
+}
+
+// Introspect returns the token details. ErrUnsupported
+// is returned if the provider does not advertise an
+// introspection endpoint.
+func (c *Config) Introspect(ctx context.Context, token *login.Token) (*login.TokenInfo, error) {
+	p, err := c.discover(ctx)
+	if err != nil {
+		return nil, api.Wrap(login.ErrUnreachable, err)
+	}
+	return c.config(p).Introspect(ctx, token)
+}
+
+// config returns the oauth2 configuration for the
+// discovered provider.
+func (c *Config) config(p *provider) *oauth2.Config {
+	scope := c.Scope
+	if len(scope) == 0 {
+		scope = defaultScope
+	}
+	style := oauth2.AuthStyleHeader
+	if !p.supportsBasicAuth() {
+		style = oauth2.AuthStyleParams
+	}
+	return &oauth2.Config{
+		AuthStyle:        style,
+		Client:           c.Client,
+		ClientID:         c.ClientID,
+		ClientSecret:     c.ClientSecret,
+		RedirectURL:      c.RedirectURL,
+		AccessTokenURL:   p.TokenEndpoint,
+		AuthorizationURL: p.AuthorizationEndpoint,
+		RevocationURL:    p.RevocationEndpoint,
+		IntrospectionURL: p.IntrospectionEndpoint,
+		Scope:            withOpenID(scope),
+		PKCE:             p.supportsPKCE(),
+		StateStore:       c.StateStore,
+		Server:           p.Issuer,
+		Identifier:       c,
+		IDTokenVerifier:  c,
+		Logger:           c.Logger,
+		Dumper:           c.Dumper,
+		AuthParams:       c.authParams(),
+		RequestParams:    c.RequestParams,
+	}
+}
+
+// authParams returns the additional authorization
+// parameters.
+func (c *Config) authParams() map[string]string {
+	params := map[string]string{}
+	for key, value := range c.AuthParams {
+		params[key] = value
+	}
+	if len(c.Prompt) != 0 {
+		params["prompt"] = c.Prompt
+	}
+	if len(c.LoginHint) != 0 {
+		params["login_hint"] = c.LoginHint
+	}
+	return params
+}
+
+func (c *Config) api() *api.Client {
+	return &api.Client{
+		Client: c.Client,
+		Dumper: c.Dumper,
+	}
+}
+
+func (c *Config) logger() logger.Logger {
+	if c.Logger == nil {
+		return logger.Discard()
+	}
+	return c.Logger
+}
+
+type handler struct {
+	conf *Config
+	next http.Handler
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// discovers the provider endpoints. If the discovery
+	// document cannot be retrieved, write the error to the
+	// context and proceed with the next http.Handler in
+	// the chain.
+	p, err := h.conf.discover(r.Context())
+	if err != nil {
+		h.conf.logger().Errorf("oidc: cannot discover provider: %s", err)
+		ctx := login.WithError(r.Context(), api.Wrap(login.ErrUnreachable, err))
+		h.next.ServeHTTP(w, r.WithContext(ctx))
+		return
+	}
+	oauth2.Handler(h.next, h.conf.config(p)).ServeHTTP(w, r)
+}
+
+// withOpenID returns the scopes including the openid scope,
+// which is required for OpenID Connect requests.
+func withOpenID(scope []string) []string {
+	for _, s := range scope {
+		if s == "openid" {
+			return scope
+		}
+	}
+	return append([]string{"openid"}, scope...)
+}
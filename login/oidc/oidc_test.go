@@ -0,0 +1,311 @@
+// Copyright 2017 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/drone/go-login/login"
+)
+
+// fakeIssuer is a minimal OpenID Provider used to test
+// the authorization flow.
+type fakeIssuer struct {
+	*httptest.Server
+	key   *rsa.PrivateKey
+	nonce string
+	claim map[string]interface{}
+}
+
+func newFakeIssuer(t *testing.T) *fakeIssuer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeIssuer{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"issuer":                           f.URL,
+			"authorization_endpoint":           f.URL + "/authorize",
+			"token_endpoint":                   f.URL + "/token",
+			"userinfo_endpoint":                f.URL + "/userinfo",
+			"jwks_uri":                         f.URL + "/jwks",
+			"code_challenge_methods_supported": []string{"plain", "S256"},
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "1",
+				"use": "sig",
+				"alg": "RS256",
+				"n":   encodeInt(key.N),
+				"e":   encodeInt(big.NewInt(int64(key.E))),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("code") != "3584d83530557fdd1f46af8289938c8ef79f9dc5" {
+			w.WriteHeader(400)
+			return
+		}
+		if r.FormValue("code_verifier") == "" {
+			w.WriteHeader(400)
+			return
+		}
+		claims := map[string]interface{}{
+			"iss":                f.URL,
+			"sub":                "248289761001",
+			"aud":                "3da54155991",
+			"exp":                time.Now().Add(time.Hour).Unix(),
+			"iat":                time.Now().Unix(),
+			"nonce":              f.nonce,
+			"name":               "Jane Doe",
+			"preferred_username": "janedoe",
+			"email":              "janedoe@example.com",
+		}
+		for k, v := range f.claim {
+			claims[k] = v
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "755bb80e5b",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"id_token":     signRSA(t, key, "RS256", "1", claims),
+		})
+	})
+	f.Server = httptest.NewServer(mux)
+	return f
+}
+
+func TestHandler(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	defer issuer.Close()
+
+	conf := &Config{
+		ClientID:     "3da54155991",
+		ClientSecret: "5012f6c60b2",
+		RedirectURL:  "https://company.com/login",
+		Issuer:       issuer.URL,
+	}
+
+	var user *login.User
+	var server string
+	var claims *Claims
+	var token *login.Token
+	h := conf.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := login.ErrorFrom(r.Context()); err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+		user = login.UserFrom(r.Context())
+		claims = ClaimsFrom(r.Context())
+		token = login.TokenFrom(r.Context())
+		server = login.ServerFrom(r.Context())
+	}))
+
+	// initiates the authorization flow.
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/login", nil)
+	h.ServeHTTP(w, r)
+
+	location, err := url.Parse(w.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := location.Path, "/authorize"; got != want {
+		t.Errorf("Want redirect to %s, got %s", want, got)
+	}
+	if got, want := location.Query().Get("scope"), "openid profile email"; got != want {
+		t.Errorf("Want scope %q, got %q", want, got)
+	}
+	if location.Query().Get("code_challenge") == "" {
+		t.Errorf("Expect PKCE code_challenge")
+	}
+	issuer.nonce = location.Query().Get("nonce")
+	if issuer.nonce == "" {
+		t.Errorf("Expect nonce parameter")
+	}
+
+	// completes the authorization flow.
+	cookies := w.Result().Cookies()
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("GET", "/login?code=3584d83530557fdd1f46af8289938c8ef79f9dc5&state="+location.Query().Get("state"), nil)
+	for _, c := range cookies {
+		r.AddCookie(c)
+	}
+	h.ServeHTTP(w, r)
+
+	if token == nil || token.Access != "755bb80e5b" {
+		t.Errorf("Expect access token in context")
+	}
+	if claims == nil {
+		t.Fatalf("Expect id_token claims in context")
+	}
+	if got, want := claims.Subject, "248289761001"; got != want {
+		t.Errorf("Want subject %s, got %s", want, got)
+	}
+	if got, want := server, issuer.URL; got != want {
+		t.Errorf("Want server %s, got %s", want, got)
+	}
+	if user == nil {
+		t.Fatalf("Expect user in context")
+	}
+	if got, want := user.Login, "janedoe"; got != want {
+		t.Errorf("Want login %s, got %s", want, got)
+	}
+	if got, want := user.Email, "janedoe@example.com"; got != want {
+		t.Errorf("Want email %s, got %s", want, got)
+	}
+}
+
+func TestHandler_InvalidNonce(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	defer issuer.Close()
+
+	conf := &Config{
+		ClientID:    "3da54155991",
+		RedirectURL: "https://company.com/login",
+		Issuer:      issuer.URL,
+	}
+
+	var err error
+	h := conf.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err = login.ErrorFrom(r.Context())
+	}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/login", nil))
+	location, _ := url.Parse(w.Header().Get("Location"))
+	issuer.nonce = "a0be7e6b6ec5"
+
+	cookies := w.Result().Cookies()
+	w = httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/login?code=3584d83530557fdd1f46af8289938c8ef79f9dc5&state="+location.Query().Get("state"), nil)
+	for _, c := range cookies {
+		r.AddCookie(c)
+	}
+	h.ServeHTTP(w, r)
+
+	if !errors.Is(err, login.ErrIDToken) {
+		t.Errorf("Want ErrIDToken, got %v", err)
+	}
+}
+
+func TestHandler_Unreachable(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	issuer.Close()
+
+	conf := &Config{
+		ClientID: "3da54155991",
+		Issuer:   issuer.URL,
+	}
+
+	var err error
+	h := conf.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err = login.ErrorFrom(r.Context())
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/login", nil))
+
+	if !errors.Is(err, login.ErrUnreachable) {
+		t.Errorf("Want ErrUnreachable, got %v", err)
+	}
+}
+
+func TestDiscover_IssuerMismatch(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	defer issuer.Close()
+
+	conf := &Config{Issuer: "https://accounts.example.com"}
+	conf.Client = issuer.Client()
+	conf.Client.Transport = rewrite(issuer.URL)
+	if _, err := conf.discover(noContext); err != errIssuer {
+		t.Errorf("Want issuer mismatch error, got %v", err)
+	}
+}
+
+func encodeInt(i *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(i.Bytes())
+}
+
+func encodeSegment(t *testing.T, v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// signRSA returns a json web token signed with the rsa key.
+func signRSA(t *testing.T, key *rsa.PrivateKey, alg, kid string, claims interface{}) string {
+	input := encodeSegment(t, map[string]string{"alg": alg, "kid": kid}) + "." + encodeSegment(t, claims)
+	digest := sha256.Sum256([]byte(input))
+	var sig []byte
+	var err error
+	if alg == "PS256" {
+		sig, err = rsa.SignPSS(rand.Reader, key, crypto.SHA256, digest[:], nil)
+	} else {
+		sig, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+// signECDSA returns a json web token signed with the ES256
+// algorithm.
+func signECDSA(t *testing.T, key *ecdsa.PrivateKey, kid string, claims interface{}) string {
+	input := encodeSegment(t, map[string]string{"alg": "ES256", "kid": kid}) + "." + encodeSegment(t, claims)
+	digest := sha256.Sum256([]byte(input))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+// rewrite returns a transport that sends all requests to
+// the target server.
+func rewrite(target string) http.RoundTripper {
+	u, _ := url.Parse(target)
+	return roundTripper(func(r *http.Request) (*http.Response, error) {
+		r = r.Clone(r.Context())
+		r.URL.Scheme = u.Scheme
+		r.URL.Host = u.Host
+		return http.DefaultTransport.RoundTrip(r)
+	})
+}
+
+type roundTripper func(*http.Request) (*http.Response, error)
+
+func (fn roundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	return fn(r)
+}
+
+func TestNilToken(t *testing.T) {
+	c := &Config{Issuer: "https://accounts.company.com"}
+	if _, err := c.Identify(context.Background(), nil); err != login.ErrToken {
+		t.Errorf("Want error %s, got %v", login.ErrToken, err)
+	}
+}
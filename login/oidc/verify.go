@@ -0,0 +1,175 @@
+// Copyright 2017 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"hash"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// leeway is the allowed clock skew when validating the
+// token expiration.
+const leeway = time.Minute
+
+var (
+	errMalformed = errors.New("oidc: malformed id_token")
+	errAlgorithm = errors.New("oidc: unsupported signing algorithm")
+	errSignature = errors.New("oidc: invalid id_token signature")
+	errAudience  = errors.New("oidc: id_token audience does not match the client id")
+	errExpired   = errors.New("oidc: id_token is expired")
+	errNonce     = errors.New("oidc: id_token nonce does not match")
+)
+
+// now returns the current time. It is a variable so that
+// it can be replaced in unit tests.
+var now = time.Now
+
+// Verify verifies the id_token signature and claims, and
+// returns a context that includes the id_token claims.
+func (c *Config) Verify(ctx context.Context, raw, nonce string) (context.Context, error) {
+	p, err := c.discover(ctx)
+	if err != nil {
+		return ctx, err
+	}
+	claims, err := c.verify(ctx, p, raw)
+	if err != nil {
+		return ctx, err
+	}
+	if claims.Issuer != p.Issuer {
+		return ctx, errIssuer
+	}
+	if !contains(claims.Audience, c.ClientID) {
+		return ctx, errAudience
+	}
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != c.ClientID {
+		return ctx, errAudience
+	}
+	if claims.Expires.IsZero() || now().After(claims.Expires.Add(leeway)) {
+		return ctx, errExpired
+	}
+	if claims.Nonce != nonce {
+		return ctx, errNonce
+	}
+	return WithClaims(ctx, claims), nil
+}
+
+// verify verifies the token signature and returns the
+// token claims.
+func (c *Config) verify(ctx context.Context, p *provider, raw string) (*Claims, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, errMalformed
+	}
+	header := struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}{}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, errMalformed
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errMalformed
+	}
+	h, err := hasher(header.Alg)
+	if err != nil {
+		return nil, err
+	}
+	key, err := c.key(ctx, p, header.Kid, header.Alg)
+	if err != nil {
+		return nil, err
+	}
+	h.Write([]byte(parts[0] + "." + parts[1]))
+	if err := verifySignature(header.Alg, key, h.Sum(nil), sig); err != nil {
+		return nil, err
+	}
+	payload := map[string]interface{}{}
+	if err := decodeSegment(parts[1], &payload); err != nil {
+		return nil, errMalformed
+	}
+	return parseClaims(payload)
+}
+
+// hasher returns the hash function for the signing
+// algorithm. The none algorithm is never accepted.
+func hasher(alg string) (hash.Hash, error) {
+	switch alg {
+	case "RS256", "PS256", "ES256":
+		return sha256.New(), nil
+	case "RS384", "PS384", "ES384":
+		return sha512.New384(), nil
+	case "RS512", "PS512", "ES512":
+		return sha512.New(), nil
+	default:
+		return nil, errAlgorithm
+	}
+}
+
+// verifySignature verifies the signature of the digest
+// using the public key.
+func verifySignature(alg string, key crypto.PublicKey, digest, sig []byte) error {
+	var hash crypto.Hash
+	switch alg[2:] {
+	case "256":
+		hash = crypto.SHA256
+	case "384":
+		hash = crypto.SHA384
+	case "512":
+		hash = crypto.SHA512
+	}
+	switch alg[:2] {
+	case "RS", "PS":
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return errSignature
+		}
+		var err error
+		if alg[:2] == "RS" {
+			err = rsa.VerifyPKCS1v15(pub, hash, digest, sig)
+		} else {
+			err = rsa.VerifyPSS(pub, hash, digest, sig, nil)
+		}
+		if err != nil {
+			return errSignature
+		}
+		return nil
+	case "ES":
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return errSignature
+		}
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if len(sig) != 2*size {
+			return errSignature
+		}
+		r := new(big.Int).SetBytes(sig[:size])
+		s := new(big.Int).SetBytes(sig[size:])
+		if !ecdsa.Verify(pub, digest, r, s) {
+			return errSignature
+		}
+		return nil
+	}
+	return errAlgorithm
+}
+
+// decodeSegment decodes a base64url encoded json segment.
+func decodeSegment(seg string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
@@ -0,0 +1,183 @@
+// Copyright 2017 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"strings"
+	"testing"
+	"time"
+)
+
+var noContext = context.Background()
+
+func TestVerify(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &Config{ClientID: "3da54155991"}
+	conf.provider = &provider{Issuer: "https://accounts.example.com"}
+	conf.keys = []*publicKey{
+		{id: "rsa", key: &rsaKey.PublicKey},
+		{id: "ec", alg: "ES256", key: &ecKey.PublicKey},
+	}
+
+	claims := func(override map[string]interface{}) map[string]interface{} {
+		out := map[string]interface{}{
+			"iss":   "https://accounts.example.com",
+			"sub":   "248289761001",
+			"aud":   "3da54155991",
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"nonce": "n-0S6_WzA2Mj",
+		}
+		for k, v := range override {
+			if v == nil {
+				delete(out, k)
+			} else {
+				out[k] = v
+			}
+		}
+		return out
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		err   error
+	}{
+		{
+			name:  "RS256",
+			token: signRSA(t, rsaKey, "RS256", "rsa", claims(nil)),
+		},
+		{
+			name:  "PS256",
+			token: signRSA(t, rsaKey, "PS256", "rsa", claims(nil)),
+		},
+		{
+			name:  "ES256",
+			token: signECDSA(t, ecKey, "ec", claims(nil)),
+		},
+		{
+			name:  "AudienceList",
+			token: signRSA(t, rsaKey, "RS256", "rsa", claims(map[string]interface{}{"aud": []string{"3da54155991", "other"}, "azp": "3da54155991"})),
+		},
+		{
+			name:  "AudienceListNoAuthorizedParty",
+			token: signRSA(t, rsaKey, "RS256", "rsa", claims(map[string]interface{}{"aud": []string{"3da54155991", "other"}})),
+			err:   errAudience,
+		},
+		{
+			name:  "Audience",
+			token: signRSA(t, rsaKey, "RS256", "rsa", claims(map[string]interface{}{"aud": "other"})),
+			err:   errAudience,
+		},
+		{
+			name:  "Issuer",
+			token: signRSA(t, rsaKey, "RS256", "rsa", claims(map[string]interface{}{"iss": "https://evil.example.com"})),
+			err:   errIssuer,
+		},
+		{
+			name:  "Expired",
+			token: signRSA(t, rsaKey, "RS256", "rsa", claims(map[string]interface{}{"exp": time.Now().Add(-time.Hour).Unix()})),
+			err:   errExpired,
+		},
+		{
+			name:  "MissingExpiry",
+			token: signRSA(t, rsaKey, "RS256", "rsa", claims(map[string]interface{}{"exp": nil})),
+			err:   errExpired,
+		},
+		{
+			name:  "Nonce",
+			token: signRSA(t, rsaKey, "RS256", "rsa", claims(map[string]interface{}{"nonce": "other"})),
+			err:   errNonce,
+		},
+		{
+			name:  "None",
+			token: encodeSegment(t, map[string]string{"alg": "none"}) + "." + encodeSegment(t, claims(nil)) + ".",
+			err:   errAlgorithm,
+		},
+		{
+			name:  "Malformed",
+			token: "eyJhbGciOiJSUzI1NiJ9",
+			err:   errMalformed,
+		},
+	}
+
+	for _, test := range tests {
+		_, err := conf.Verify(noContext, test.token, "n-0S6_WzA2Mj")
+		if err != test.err {
+			t.Errorf("%s: want error %v, got %v", test.name, test.err, err)
+		}
+	}
+}
+
+func TestVerify_Signature(t *testing.T) {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	other, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	conf := &Config{ClientID: "3da54155991"}
+	conf.provider = &provider{Issuer: "https://accounts.example.com"}
+	conf.keys = []*publicKey{{id: "1", key: &key.PublicKey}}
+
+	token := signRSA(t, other, "RS256", "1", map[string]interface{}{
+		"iss": "https://accounts.example.com",
+		"sub": "248289761001",
+		"aud": "3da54155991",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	if _, err := conf.Verify(noContext, token, ""); err != errSignature {
+		t.Errorf("Want signature error, got %v", err)
+	}
+
+	// the payload is altered after signing.
+	token = signRSA(t, key, "RS256", "1", map[string]interface{}{
+		"iss": "https://accounts.example.com",
+		"sub": "248289761001",
+		"aud": "3da54155991",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	parts := strings.Split(token, ".")
+	parts[1] = encodeSegment(t, map[string]interface{}{
+		"iss": "https://accounts.example.com",
+		"sub": "1",
+		"aud": "3da54155991",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	if _, err := conf.Verify(noContext, strings.Join(parts, "."), ""); err != errSignature {
+		t.Errorf("Want signature error, got %v", err)
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	jwk := &jsonWebKey{
+		Kty: "EC",
+		Crv: "P-256",
+		X:   encodeInt(key.X),
+		Y:   encodeInt(key.Y),
+	}
+	pub, err := jwk.parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !key.PublicKey.Equal(pub) {
+		t.Errorf("Want parsed key to equal the ecdsa public key")
+	}
+
+	jwk.Y = encodeInt(key.X)
+	if _, err := jwk.parse(); err == nil {
+		t.Errorf("Want error for point not on curve")
+	}
+}
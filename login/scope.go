@@ -0,0 +1,43 @@
+// Copyright 2017 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package login
+
+import "strings"
+
+// ParseScopes parses a list of scopes separated by spaces
+// or commas, the forms used by authorization servers to
+// report the granted scopes.
+func ParseScopes(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return r == ' ' || r == ','
+	})
+}
+
+// CheckScopes returns an error of kind ErrScope if the
+// token was not granted all of the requested scopes. No
+// error is returned if the provider did not report the
+// granted scopes.
+func CheckScopes(token *Token, requested []string) error {
+	if !token.ScopesReported {
+		return nil
+	}
+	granted := map[string]bool{}
+	for _, scope := range token.Scopes {
+		granted[scope] = true
+	}
+	var missing []string
+	for _, scope := range requested {
+		if !granted[scope] {
+			missing = append(missing, scope)
+		}
+	}
+	if len(missing) != 0 {
+		return &Error{
+			Kind:        ErrScope,
+			Description: "missing " + strings.Join(missing, ", "),
+		}
+	}
+	return nil
+}
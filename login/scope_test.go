@@ -0,0 +1,51 @@
+// Copyright 2017 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package login
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseScopes(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{in: "repo,user:email", want: []string{"repo", "user:email"}},
+		{in: "api read_user", want: []string{"api", "read_user"}},
+		{in: "repo, user:email", want: []string{"repo", "user:email"}},
+		{in: "", want: []string{}},
+	}
+	for _, test := range tests {
+		if got := ParseScopes(test.in); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Want scopes %q for %q, got %q", test.want, test.in, got)
+		}
+	}
+}
+
+func TestCheckScopes(t *testing.T) {
+	token := &Token{Scopes: []string{"repo"}, ScopesReported: true}
+	if err := CheckScopes(token, []string{"repo"}); err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	err := CheckScopes(token, []string{"repo", "user:email"})
+	if !errors.Is(err, ErrScope) {
+		t.Errorf("Want error %v, got %v", ErrScope, err)
+	}
+	if got, want := err.Error(), "Insufficient scope: missing user:email"; got != want {
+		t.Errorf("Want error message %q, got %q", want, got)
+	}
+	// the granted scopes are not reported.
+	if err := CheckScopes(&Token{}, []string{"repo"}); err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	// the token is granted no scopes.
+	err = CheckScopes(&Token{ScopesReported: true}, []string{"repo"})
+	if !errors.Is(err, ErrScope) {
+		t.Errorf("Want error %v, got %v", ErrScope, err)
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2017 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package login
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// expiryDelta is the default duration before expiration at
+// which the token is considered expired and refreshed.
+const expiryDelta = time.Minute
+
+// TokenSource returns a valid authorization token,
+// transparently refreshing the token when it is about to
+// expire.
+type TokenSource struct {
+	// Refresher is used to refresh the token.
+	Refresher Refresher
+
+	// Expiry is the duration before token expiration at
+	// which the token is refreshed. If zero, the token is
+	// refreshed one minute before expiration.
+	Expiry time.Duration
+
+	// OnRefresh is invoked with the new token after a
+	// successful refresh, giving the caller the opportunity
+	// to persist the rotated refresh token. It is invoked
+	// while the TokenSource is locked, and must not call
+	// Token, which would deadlock.
+	OnRefresh func(*Token)
+
+	mu    sync.Mutex
+	token *Token
+}
+
+// NewTokenSource returns a TokenSource that returns token
+// until it expires, and then uses the Refresher to obtain
+// a new token. The optional fn is invoked with each new
+// token.
+func NewTokenSource(r Refresher, token *Token, fn func(*Token)) *TokenSource {
+	return &TokenSource{
+		Refresher: r,
+		OnRefresh: fn,
+		token:     token,
+	}
+}
+
+// Token returns a valid token, refreshing the token if it
+// is about to expire. Concurrent calls are serialized such
+// that only one refresh request is in flight at a time.
+// ErrToken is returned if the source has no token.
+func (s *TokenSource) Token(ctx context.Context) (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token == nil {
+		return nil, ErrToken
+	}
+	if !s.expired() {
+		return s.token, nil
+	}
+	token, err := s.Refresher.Refresh(ctx, s.token)
+	if err != nil {
+		return nil, err
+	}
+	s.token = token
+	if s.OnRefresh != nil {
+		s.OnRefresh(token)
+	}
+	return token, nil
+}
+
+// expired reports whether the token is refreshable and is
+// expired or about to expire.
+func (s *TokenSource) expired() bool {
+	if s.token.Refresh == "" || s.token.Expires.IsZero() {
+		return false
+	}
+	delta := s.Expiry
+	if delta == 0 {
+		delta = expiryDelta
+	}
+	return time.Now().Add(delta).After(s.token.Expires)
+}
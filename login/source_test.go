@@ -0,0 +1,143 @@
+// Copyright 2017 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package login
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type mockRefresher struct {
+	count int32
+	err   error
+}
+
+func (m *mockRefresher) Refresh(ctx context.Context, token *Token) (*Token, error) {
+	atomic.AddInt32(&m.count, 1)
+	if m.err != nil {
+		return nil, m.err
+	}
+	time.Sleep(10 * time.Millisecond)
+	return &Token{
+		Access:  "9b2b4b1c7f",
+		Refresh: "a7b1c3d5e9",
+		Expires: time.Now().Add(time.Hour),
+	}, nil
+}
+
+func TestTokenSource(t *testing.T) {
+	token := &Token{
+		Access:  "755bb80e5b",
+		Refresh: "e08f3fa43e",
+		Expires: time.Now().Add(time.Hour),
+	}
+	r := new(mockRefresher)
+	s := NewTokenSource(r, token, nil)
+	got, err := s.Token(context.Background())
+	if err != nil {
+		t.Error(err)
+	}
+	if got != token {
+		t.Errorf("Expect unexpired token returned")
+	}
+	if r.count != 0 {
+		t.Errorf("Expect unexpired token not refreshed")
+	}
+}
+
+func TestTokenSourceRefresh(t *testing.T) {
+	token := &Token{
+		Access:  "755bb80e5b",
+		Refresh: "e08f3fa43e",
+		Expires: time.Now().Add(30 * time.Second),
+	}
+	var rotated *Token
+	r := new(mockRefresher)
+	s := NewTokenSource(r, token, func(t *Token) {
+		rotated = t
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got, err := s.Token(context.Background())
+			if err != nil {
+				t.Error(err)
+			} else if got.Access != "9b2b4b1c7f" {
+				t.Errorf("Want refreshed access token, got %s", got.Access)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got, want := r.count, int32(1); got != want {
+		t.Errorf("Want %d refresh requests, got %d", want, got)
+	}
+	if rotated == nil || rotated.Refresh != "a7b1c3d5e9" {
+		t.Errorf("Expect refresh callback with rotated token")
+	}
+}
+
+func TestTokenSourceNoRefresh(t *testing.T) {
+	// tokens without a refresh token, such as GitHub oauth
+	// tokens, are never refreshed.
+	token := &Token{
+		Access:  "755bb80e5b",
+		Expires: time.Now(),
+	}
+	r := new(mockRefresher)
+	s := NewTokenSource(r, token, nil)
+	if got, _ := s.Token(context.Background()); got != token {
+		t.Errorf("Expect token returned")
+	}
+	if r.count != 0 {
+		t.Errorf("Expect token without refresh token not refreshed")
+	}
+}
+
+func TestTokenSourceNoExpiry(t *testing.T) {
+	// tokens with a refresh token but without an expiry
+	// are not refreshed.
+	token := &Token{
+		Access:  "755bb80e5b",
+		Refresh: "e08f3fa43e",
+	}
+	r := new(mockRefresher)
+	s := NewTokenSource(r, token, nil)
+	for i := 0; i < 2; i++ {
+		if got, _ := s.Token(context.Background()); got != token {
+			t.Errorf("Expect token returned")
+		}
+	}
+	if r.count != 0 {
+		t.Errorf("Expect token without expiry not refreshed")
+	}
+}
+
+func TestTokenSourceError(t *testing.T) {
+	token := &Token{
+		Access:  "755bb80e5b",
+		Refresh: "e08f3fa43e",
+		Expires: time.Now(),
+	}
+	r := &mockRefresher{err: errors.New("invalid_grant")}
+	s := NewTokenSource(r, token, nil)
+	if _, err := s.Token(context.Background()); err != r.err {
+		t.Errorf("Want refresh error, got %v", err)
+	}
+}
+
+func TestTokenSourceNilToken(t *testing.T) {
+	s := NewTokenSource(new(mockRefresher), nil, nil)
+	if _, err := s.Token(context.Background()); err != ErrToken {
+		t.Errorf("Want error %s, got %v", ErrToken, err)
+	}
+}
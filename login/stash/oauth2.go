@@ -0,0 +1,105 @@
+// Copyright 2018 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stash
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/drone/go-login/login"
+	"github.com/drone/go-login/login/internal/api"
+	"github.com/drone/go-login/login/logger"
+	"github.com/drone/go-login/login/oauth2"
+)
+
+var (
+	_ login.Middleware   = (*OAuth2Config)(nil)
+	_ login.Refresher    = (*OAuth2Config)(nil)
+	_ login.Identifier   = (*OAuth2Config)(nil)
+	_ login.Revoker      = (*OAuth2Config)(nil)
+	_ login.Introspector = (*OAuth2Config)(nil)
+)
+
+// OAuth2Config configures the Bitbucket Data Center
+// OAuth2 authorization middleware. The application is
+// registered as an incoming application link.
+type OAuth2Config struct {
+	Address      string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scope        []string
+	Client       *http.Client
+	Logger       logger.Logger
+	Dumper       logger.Dumper
+	PKCE         bool
+	StateStore   login.StateStore
+	FetchUser    bool
+}
+
+// Handler returns a http.Handler that runs h at the
+// completion of the Bitbucket Data Center authorization
+// flow. The authorization details are available to h in
+// the http.Request context.
+func (c *OAuth2Config) Handler(h http.Handler) http.Handler {
+	return oauth2.Handler(h, c.config())
+}
+
+// Refresh refreshes the Bitbucket Data Center
+// authorization token.
+func (c *OAuth2Config) Refresh(ctx context.Context, token *login.Token) (*login.Token, error) {
+	return c.config().Refresh(ctx, token)
+}
+
+// Revoke returns ErrUnsupported. Bitbucket Data Center does
+// not provide an oauth2 token revocation endpoint.
+func (c *OAuth2Config) Revoke(ctx context.Context, token *login.Token) error {
+	return login.ErrUnsupported
+}
+
+// Identify returns the Bitbucket Data Center user that
+// authorized the token.
+func (c *OAuth2Config) Identify(ctx context.Context, token *login.Token) (*login.User, error) {
+	if token == nil {
+		return nil, login.ErrToken
+	}
+	client := &api.Client{
+		Client:    c.Client,
+		Dumper:    c.Dumper,
+		Authorize: api.Bearer(token.Access),
+	}
+	return identify(ctx, client, c.Address)
+}
+
+// Introspect returns the Bitbucket Data Center token
+// details.
+func (c *OAuth2Config) Introspect(ctx context.Context, token *login.Token) (*login.TokenInfo, error) {
+	return api.Introspect(c.Identify(ctx, token))
+}
+
+// config returns the oauth2 configuration.
+func (c *OAuth2Config) config() *oauth2.Config {
+	server := strings.TrimSuffix(c.Address, "/")
+	conf := &oauth2.Config{
+		AuthStyle:        oauth2.AuthStyleParams,
+		Client:           c.Client,
+		ClientID:         c.ClientID,
+		ClientSecret:     c.ClientSecret,
+		RedirectURL:      c.RedirectURL,
+		AccessTokenURL:   server + "/rest/oauth2/latest/token",
+		AuthorizationURL: server + "/rest/oauth2/latest/authorize",
+		Scope:            c.Scope,
+		Logger:           c.Logger,
+		Dumper:           c.Dumper,
+		PKCE:             c.PKCE,
+		StateStore:       c.StateStore,
+		Server:           server,
+	}
+	if c.FetchUser {
+		conf.Identifier = c
+	}
+	return conf
+}
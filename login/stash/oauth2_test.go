@@ -0,0 +1,63 @@
+// Copyright 2018 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stash
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/drone/go-login/login"
+)
+
+func TestOAuth2Config(t *testing.T) {
+	conf := &OAuth2Config{Address: "https://bitbucket.company.com/"}
+	c := conf.config()
+	if got, want := c.AuthorizationURL, "https://bitbucket.company.com/rest/oauth2/latest/authorize"; got != want {
+		t.Errorf("Want authorization url %s, got %s", want, got)
+	}
+	if got, want := c.AccessTokenURL, "https://bitbucket.company.com/rest/oauth2/latest/token"; got != want {
+		t.Errorf("Want access token url %s, got %s", want, got)
+	}
+	if got, want := c.Server, "https://bitbucket.company.com"; got != want {
+		t.Errorf("Want server %s, got %s", want, got)
+	}
+}
+
+func TestOAuth2Identify(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/plugins/servlet/applinks/whoami", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer 755bb80e5b" {
+			w.WriteHeader(401)
+			return
+		}
+		w.Write([]byte("janedoe\n"))
+	})
+	mux.HandleFunc("/rest/api/1.0/users/janedoe", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(&user{
+			ID:    1,
+			Name:  "janedoe",
+			Slug:  "janedoe",
+			Email: "janedoe@example.com",
+			Full:  "Jane Doe",
+		})
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	conf := &OAuth2Config{Address: ts.URL}
+	user, err := conf.Identify(context.Background(), &login.Token{Access: "755bb80e5b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := user.Login, "janedoe"; got != want {
+		t.Errorf("Want login %s, got %s", want, got)
+	}
+	if got, want := user.Avatar, ts.URL+"/users/janedoe/avatar.png"; got != want {
+		t.Errorf("Want avatar %s, got %s", want, got)
+	}
+}
@@ -0,0 +1,208 @@
+// Copyright 2018 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stash
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/drone/go-login/login"
+	"github.com/drone/go-login/login/internal/api"
+	"github.com/drone/go-login/login/internal/password"
+	"github.com/drone/go-login/login/logger"
+)
+
+var (
+	_ login.Middleware   = (*PasswordConfig)(nil)
+	_ login.Identifier   = (*PasswordConfig)(nil)
+	_ login.Revoker      = (*PasswordConfig)(nil)
+	_ login.Introspector = (*PasswordConfig)(nil)
+)
+
+// pageSize is the number of tokens requested per page.
+const pageSize = 100
+
+// default permissions granted to the access token.
+var defaultPermissions = []string{"PROJECT_READ", "REPO_READ"}
+
+// PasswordConfig configures the Bitbucket Data Center
+// username and password login middleware. The credentials
+// are exchanged for a personal HTTP access token.
+type PasswordConfig struct {
+	Label       string
+	Login       string
+	Address     string
+	Permissions []string
+	ExpiryDays  int
+	Client      *http.Client
+	Logger      logger.Logger
+	Dumper      logger.Dumper
+	FetchUser   bool
+}
+
+// Handler returns a http.Handler that runs h at the
+// completion of the Bitbucket Data Center login flow. The
+// access token is available to h in the http.Request
+// context.
+func (c *PasswordConfig) Handler(h http.Handler) http.Handler {
+	v := &password.Handler{
+		Next:   h,
+		Name:   "stash",
+		Login:  c.Login,
+		Mint:   c.minter().mint,
+		Logger: c.Logger,
+	}
+	if c.FetchUser {
+		v.Identifier = c
+	}
+	return v
+}
+
+// minter returns the access token minter.
+func (c *PasswordConfig) minter() *minter {
+	v := &minter{
+		label:       c.Label,
+		server:      strings.TrimSuffix(c.Address, "/"),
+		permissions: c.Permissions,
+		expiry:      c.ExpiryDays,
+		client:      c.Client,
+		dumper:      c.Dumper,
+	}
+	if v.label == "" {
+		v.label = "default"
+	}
+	if len(v.permissions) == 0 {
+		v.permissions = defaultPermissions
+	}
+	return v
+}
+
+// Revoke returns ErrUnsupported. Bitbucket Data Center
+// requires the username and password to delete an access
+// token.
+func (c *PasswordConfig) Revoke(ctx context.Context, token *login.Token) error {
+	return login.ErrUnsupported
+}
+
+// Identify returns the Bitbucket Data Center user that
+// owns the access token.
+func (c *PasswordConfig) Identify(ctx context.Context, token *login.Token) (*login.User, error) {
+	if token == nil {
+		return nil, login.ErrToken
+	}
+	client := &api.Client{
+		Client:    c.Client,
+		Dumper:    c.Dumper,
+		Authorize: api.Bearer(token.Access),
+	}
+	return identify(ctx, client, c.Address)
+}
+
+// Introspect returns the Bitbucket Data Center token
+// details.
+func (c *PasswordConfig) Introspect(ctx context.Context, token *login.Token) (*login.TokenInfo, error) {
+	return api.Introspect(c.Identify(ctx, token))
+}
+
+// accessToken is a Bitbucket Data Center HTTP access
+// token. The token value is only returned when the
+// token is created.
+type accessToken struct {
+	ID          string   `json:"id,omitempty"`
+	Name        string   `json:"name"`
+	Token       string   `json:"token,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+	ExpiryDays  int      `json:"expiryDays,omitempty"`
+}
+
+// page is a page of Bitbucket Data Center access tokens.
+type page struct {
+	Values        []*accessToken `json:"values"`
+	IsLastPage    bool           `json:"isLastPage"`
+	NextPageStart int            `json:"nextPageStart"`
+}
+
+// minter creates access tokens using the username and
+// password.
+type minter struct {
+	label       string
+	server      string
+	permissions []string
+	expiry      int
+	client      *http.Client
+	dumper      logger.Dumper
+}
+
+// mint creates an access token and returns the token
+// value. Bitbucket Data Center does not support one-time
+// passwords in the access token api, so otp is ignored.
+func (m *minter) mint(ctx context.Context, user, pass, otp string) (string, error) {
+	token, err := m.createToken(ctx, user, pass)
+	if err != nil {
+		return "", err
+	}
+	return token.Token, nil
+}
+
+// createToken creates an access token for the user. The
+// token value cannot be retrieved after creation, so an
+// existing token with the same label is revoked and
+// replaced.
+func (m *minter) createToken(ctx context.Context, user, pass string) (*accessToken, error) {
+	client := &api.Client{
+		Client:    m.client,
+		Dumper:    m.dumper,
+		Authorize: api.Basic(user, pass),
+	}
+	path := m.server + "/rest/access-tokens/1.0/users/" + url.PathEscape(user)
+
+	var tokens []*accessToken
+	for start := 0; ; {
+		out := new(page)
+		query := fmt.Sprintf("?start=%d&limit=%d", start, pageSize)
+		if err := client.Get(ctx, path+query, out); err != nil {
+			return nil, exchangeError(err)
+		}
+		tokens = append(tokens, out.Values...)
+		if out.IsLastPage || out.NextPageStart <= start {
+			break
+		}
+		start = out.NextPageStart
+	}
+	for _, token := range tokens {
+		if token.Name != m.label {
+			continue
+		}
+		if err := client.Do(ctx, "DELETE", path+"/"+url.PathEscape(token.ID), nil, nil); err != nil {
+			return nil, exchangeError(err)
+		}
+	}
+
+	in := &accessToken{
+		Name:        m.label,
+		Permissions: m.permissions,
+		ExpiryDays:  m.expiry,
+	}
+	token := new(accessToken)
+	if err := client.Do(ctx, "PUT", path, in, token); err != nil {
+		return nil, exchangeError(err)
+	}
+	return token, nil
+}
+
+// exchangeError converts the error returned when creating
+// the access token to a login error.
+func exchangeError(err error) error {
+	if e, ok := err.(*api.Error); ok {
+		switch e.Status {
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return &login.Error{Kind: login.ErrInvalidCredentials, Err: err}
+		}
+	}
+	return api.Wrap(login.ErrTokenExchange, err)
+}
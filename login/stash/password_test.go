@@ -0,0 +1,150 @@
+// Copyright 2018 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stash
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/drone/go-login/login"
+)
+
+func TestPasswordLogin(t *testing.T) {
+	var deleted []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rest/access-tokens/1.0/users/janedoe", func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, _ := r.BasicAuth(); user != "janedoe" || pass != "password" {
+			w.WriteHeader(401)
+			return
+		}
+		switch r.Method {
+		case "GET":
+			// the tokens are returned one per page.
+			if r.FormValue("start") == "1" {
+				json.NewEncoder(w).Encode(&page{
+					Values:     []*accessToken{{ID: "192837465", Name: "drone"}},
+					IsLastPage: true,
+				})
+				return
+			}
+			json.NewEncoder(w).Encode(&page{
+				Values:        []*accessToken{{ID: "564738291", Name: "other"}},
+				NextPageStart: 1,
+			})
+		case "PUT":
+			in := new(accessToken)
+			json.NewDecoder(r.Body).Decode(in)
+			if in.Name != "drone" || strings.Join(in.Permissions, ",") != "PROJECT_READ,REPO_READ" {
+				w.WriteHeader(400)
+				return
+			}
+			json.NewEncoder(w).Encode(&accessToken{ID: "918273645", Name: in.Name, Token: "NzU1YmI4MGU1Yjo"})
+		}
+	})
+	mux.HandleFunc("/rest/access-tokens/1.0/users/janedoe/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "DELETE" {
+			deleted = append(deleted, strings.TrimPrefix(r.URL.Path, "/rest/access-tokens/1.0/users/janedoe/"))
+		}
+		w.WriteHeader(204)
+	})
+	mux.HandleFunc("/plugins/servlet/applinks/whoami", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer NzU1YmI4MGU1Yjo" {
+			w.WriteHeader(401)
+			return
+		}
+		w.Write([]byte("janedoe"))
+	})
+	mux.HandleFunc("/rest/api/1.0/users/janedoe", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(&user{ID: 1, Name: "janedoe", Slug: "janedoe"})
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	conf := &PasswordConfig{
+		Label:     "drone",
+		Address:   ts.URL,
+		FetchUser: true,
+	}
+
+	var token *login.Token
+	var user *login.User
+	var err error
+	h := conf.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token = login.TokenFrom(r.Context())
+		user = login.UserFrom(r.Context())
+		err = login.ErrorFrom(r.Context())
+	}))
+
+	data := url.Values{"username": {"janedoe"}, "password": {"password"}}
+	r := httptest.NewRequest("POST", "/login", strings.NewReader(data.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if got, want := token.Access, "NzU1YmI4MGU1Yjo"; got != want {
+		t.Errorf("Want access token %s, got %s", want, got)
+	}
+	if got, want := strings.Join(deleted, ","), "192837465"; got != want {
+		t.Errorf("Want existing token %s revoked, got %s", want, got)
+	}
+	if user == nil || user.Login != "janedoe" {
+		t.Errorf("Expect user janedoe in context")
+	}
+
+	// invalid credentials.
+	data = url.Values{"username": {"janedoe"}, "password": {"invalid"}}
+	r = httptest.NewRequest("POST", "/login", strings.NewReader(data.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	if !errors.Is(err, login.ErrInvalidCredentials) {
+		t.Errorf("Want ErrInvalidCredentials, got %v", err)
+	}
+}
+
+func TestPasswordRedirect(t *testing.T) {
+	conf := &PasswordConfig{
+		Login:   "/login/form",
+		Address: "https://bitbucket.company.com",
+	}
+	h := conf.Handler(http.NotFoundHandler())
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/login", nil))
+
+	if got, want := w.Code, 303; got != want {
+		t.Errorf("Want status code %d, got %d", want, got)
+	}
+	if got, want := w.Header().Get("Location"), "/login/form"; got != want {
+		t.Errorf("Want redirect location %s, got %s", want, got)
+	}
+}
+
+func TestNilToken(t *testing.T) {
+	tests := []interface {
+		Identify(context.Context, *login.Token) (*login.User, error)
+		Introspect(context.Context, *login.Token) (*login.TokenInfo, error)
+	}{
+		&Config{},
+		&PasswordConfig{},
+		&OAuth2Config{},
+	}
+	for _, c := range tests {
+		if _, err := c.Identify(context.Background(), nil); err != login.ErrToken {
+			t.Errorf("Want error %s, got %v", login.ErrToken, err)
+		}
+		if _, err := c.Introspect(context.Background(), nil); err != login.ErrToken {
+			t.Errorf("Want error %s, got %v", login.ErrToken, err)
+		}
+	}
+}
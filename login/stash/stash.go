@@ -5,6 +5,7 @@
 package stash
 
 import (
+	"context"
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
@@ -15,9 +16,15 @@ import (
 
 	"github.com/drone/go-login/login"
 	"github.com/drone/go-login/login/internal/oauth1"
+	"github.com/drone/go-login/login/logger"
 )
 
-var _ login.Middleware = (*Config)(nil)
+var (
+	_ login.Middleware   = (*Config)(nil)
+	_ login.Identifier   = (*Config)(nil)
+	_ login.Revoker      = (*Config)(nil)
+	_ login.Introspector = (*Config)(nil)
+)
 
 const (
 	requestTokenURL   = "%s/plugins/servlet/oauth/request-token"
@@ -34,6 +41,9 @@ type Config struct {
 	CallbackURL    string
 	PrivateKey     *rsa.PrivateKey
 	Client         *http.Client
+	Logger         logger.Logger
+	Dumper         logger.Dumper
+	FetchUser      bool
 }
 
 // Handler returns a http.Handler that runs h at the
@@ -41,11 +51,22 @@ type Config struct {
 // authorization details are available to h in the
 // http.Request context.
 func (c *Config) Handler(h http.Handler) http.Handler {
+	return oauth1.Handler(h, c.config())
+}
+
+// Revoke returns ErrUnsupported. Bitbucket Server does not
+// provide an oauth1 token revocation endpoint.
+func (c *Config) Revoke(ctx context.Context, token *login.Token) error {
+	return login.ErrUnsupported
+}
+
+// config returns the oauth1 configuration.
+func (c *Config) config() *oauth1.Config {
 	server := strings.TrimSuffix(c.Address, "/")
 	signer := &oauth1.RSASigner{
 		PrivateKey: c.PrivateKey,
 	}
-	return oauth1.Handler(h, &oauth1.Config{
+	conf := &oauth1.Config{
 		Signer:           signer,
 		Client:           c.Client,
 		ConsumerKey:      c.ConsumerKey,
@@ -54,7 +75,13 @@ func (c *Config) Handler(h http.Handler) http.Handler {
 		AccessTokenURL:   fmt.Sprintf(accessTokenURL, server),
 		AuthorizationURL: fmt.Sprintf(authorizeTokenURL, server),
 		RequestTokenURL:  fmt.Sprintf(requestTokenURL, server),
-	})
+		Logger:           c.Logger,
+		Dumper:           c.Dumper,
+	}
+	if c.FetchUser {
+		conf.Identifier = c
+	}
+	return conf
 }
 
 // ParsePrivateKeyFile is a helper function that parses an
@@ -0,0 +1,102 @@
+// Copyright 2018 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stash
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/drone/go-login/login"
+	"github.com/drone/go-login/login/internal/api"
+)
+
+type user struct {
+	ID    int64  `json:"id"`
+	Name  string `json:"name"`
+	Slug  string `json:"slug"`
+	Email string `json:"emailAddress"`
+	Full  string `json:"displayName"`
+}
+
+// Identify returns the Bitbucket Server user that
+// authorized the token.
+func (c *Config) Identify(ctx context.Context, token *login.Token) (*login.User, error) {
+	if token == nil {
+		return nil, login.ErrToken
+	}
+	return identify(ctx, c.api(token), c.Address)
+}
+
+// Introspect returns the Bitbucket Server token details.
+func (c *Config) Introspect(ctx context.Context, token *login.Token) (*login.TokenInfo, error) {
+	return api.Introspect(c.Identify(ctx, token))
+}
+
+// identify returns the Bitbucket Server user using the
+// authorized api client.
+func identify(ctx context.Context, client *api.Client, address string) (*login.User, error) {
+	server := strings.TrimSuffix(address, "/")
+	username, err := whoami(ctx, client, server)
+	if err != nil {
+		return nil, api.Wrap(login.ErrIdentity, err)
+	}
+	out := new(user)
+	err = client.Get(ctx, server+"/rest/api/1.0/users/"+url.PathEscape(username), out)
+	if err != nil {
+		return nil, api.Wrap(login.ErrIdentity, err)
+	}
+	return &login.User{
+		ID:     strconv.FormatInt(out.ID, 10),
+		Login:  out.Name,
+		Name:   out.Full,
+		Email:  out.Email,
+		Avatar: server + "/users/" + out.Slug + "/avatar.png",
+		Host:   api.Host(server),
+	}, nil
+}
+
+// whoami returns the username of the user that authorized
+// the token. The username is returned in plain text by the
+// application links whoami endpoint.
+func whoami(ctx context.Context, client *api.Client, server string) (string, error) {
+	req, err := client.NewRequest(ctx, "GET", server+"/plugins/servlet/applinks/whoami", nil)
+	if err != nil {
+		return "", err
+	}
+	res, err := client.Send(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	if res.StatusCode > 299 {
+		return "", &api.Error{Status: res.StatusCode}
+	}
+	b, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return "", err
+	}
+	username := strings.TrimSpace(string(b))
+	if username == "" {
+		return "", &api.Error{Status: http.StatusUnauthorized}
+	}
+	return username, nil
+}
+
+// api returns an api client that signs requests with the
+// oauth1 token credentials.
+func (c *Config) api(token *login.Token) *api.Client {
+	conf := c.config()
+	return &api.Client{
+		Client: c.Client,
+		Dumper: c.Dumper,
+		Authorize: func(req *http.Request) error {
+			return conf.SignRequest(req, token)
+		},
+	}
+}
@@ -0,0 +1,382 @@
+// Copyright 2017 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package login
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// default state cookie settings.
+const (
+	defaultCookieName = "_oauth_state_"
+	defaultCookiePath = "/"
+	defaultStateTTL   = 30 * time.Minute
+)
+
+// State represents the authorization request state that is
+// persisted between the authorization redirect and the
+// authorization callback.
+type State struct {
+	// Value is the opaque state value sent to the
+	// authorization server.
+	Value string `json:"state"`
+
+	// Verifier is the PKCE code verifier.
+	Verifier string `json:"verifier,omitempty"`
+
+	// ReturnTo is the url the user requested before the
+	// authorization flow started.
+	ReturnTo string `json:"return_to,omitempty"`
+
+	// Nonce is the OpenID Connect nonce used to associate
+	// the id_token with the authorization request.
+	Nonce string `json:"nonce,omitempty"`
+
+	// Upgrade is true if the authorization requests
+	// additional scopes for a user that is already logged
+	// in, in which case Scopes are the additional scopes.
+	Upgrade bool     `json:"upgrade,omitempty"`
+	Scopes  []string `json:"scopes,omitempty"`
+}
+
+// StateStore persists the authorization state between the
+// authorization redirect and the authorization callback.
+type StateStore interface {
+	// Create persists the state for the request.
+	Create(w http.ResponseWriter, r *http.Request, state *State) error
+
+	// Validate returns the state persisted for the request.
+	// ErrState is returned if no state is persisted or the
+	// persisted state does not match the value.
+	Validate(r *http.Request, value string) (*State, error)
+
+	// Delete removes the state persisted for the request.
+	Delete(w http.ResponseWriter, r *http.Request) error
+}
+
+// Namespacer is implemented by state stores that can
+// isolate the state of concurrent authorization flows,
+// such as flows to different servers.
+type Namespacer interface {
+	// Namespace returns a StateStore that persists the
+	// state separately from other namespaces.
+	Namespace(name string) StateStore
+}
+
+// CookieStore is a StateStore that persists the state in
+// a session cookie. The zero value is ready to use.
+type CookieStore struct {
+	// Name is the cookie name. If empty, the default cookie
+	// name _oauth_state_ is used. Use a unique name for each
+	// handler when running multiple handlers on one domain.
+	Name string
+
+	// Path is the cookie path. If empty, the root path is
+	// used.
+	Path string
+
+	// Domain is the cookie domain. If empty, the cookie is
+	// a host-only cookie.
+	Domain string
+
+	// TTL is the cookie lifetime. If zero, the cookie
+	// expires after 30 minutes.
+	TTL time.Duration
+
+	// Secure instructs the browser to only send the cookie
+	// over https. The cookie is always marked secure when
+	// the request is received over tls.
+	Secure bool
+
+	// SameSite is the cookie same-site policy. If unset,
+	// the lax policy is used, which permits the cookie in
+	// the top-level redirect from the authorization server.
+	SameSite http.SameSite
+
+	// Keys are used to sign the cookie value with
+	// HMAC-SHA256 to prevent forgery. The first key is used
+	// to sign the cookie and all keys are used to verify
+	// the signature, which allows keys to be rotated. If
+	// empty, the cookie value is not signed.
+	Keys [][]byte
+}
+
+// cookieValue is the value encoded in the session cookie.
+type cookieValue struct {
+	State
+	Expires int64 `json:"exp"`
+}
+
+// Create persists the state in the session cookie.
+func (s *CookieStore) Create(w http.ResponseWriter, r *http.Request, state *State) error {
+	data, err := json.Marshal(&cookieValue{
+		State:   *state,
+		Expires: time.Now().Add(s.ttl()).Unix(),
+	})
+	if err != nil {
+		return err
+	}
+	value := base64.RawURLEncoding.EncodeToString(data)
+	if len(s.Keys) != 0 {
+		value = value + "." + sign(s.Keys[0], value)
+	}
+	http.SetCookie(w, s.cookie(r, value, int(s.ttl()/time.Second)))
+	return nil
+}
+
+// Validate returns the state persisted in the session
+// cookie if it matches the value.
+func (s *CookieStore) Validate(r *http.Request, value string) (*State, error) {
+	state, err := s.read(r)
+	if err != nil {
+		return nil, err
+	}
+	if !equal(state.Value, value) {
+		return nil, ErrState
+	}
+	return state, nil
+}
+
+// Delete deletes the session cookie.
+func (s *CookieStore) Delete(w http.ResponseWriter, r *http.Request) error {
+	http.SetCookie(w, s.cookie(r, "", -1))
+	return nil
+}
+
+// read returns the state persisted in the session cookie.
+// ErrState is returned if the cookie signature is invalid
+// or the cookie is expired.
+func (s *CookieStore) read(r *http.Request) (*State, error) {
+	cookie, err := r.Cookie(s.name())
+	if err != nil {
+		return nil, ErrState
+	}
+	value := cookie.Value
+	if len(s.Keys) != 0 {
+		parts := strings.SplitN(value, ".", 2)
+		if len(parts) != 2 || !s.verify(parts[0], parts[1]) {
+			return nil, ErrState
+		}
+		value = parts[0]
+	}
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrState
+	}
+	v := new(cookieValue)
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, ErrState
+	}
+	if v.Value == "" || time.Now().Unix() > v.Expires {
+		return nil, ErrState
+	}
+	return &v.State, nil
+}
+
+// verify reports whether the signature is valid for the
+// value using any of the keys.
+func (s *CookieStore) verify(value, signature string) bool {
+	for _, key := range s.Keys {
+		if hmac.Equal([]byte(sign(key, value)), []byte(signature)) {
+			return true
+		}
+	}
+	return false
+}
+
+// cookie returns a session cookie with the value and
+// max age.
+func (s *CookieStore) cookie(r *http.Request, value string, maxAge int) *http.Cookie {
+	cookie := &http.Cookie{
+		Name:     s.name(),
+		Value:    value,
+		Path:     s.Path,
+		Domain:   s.Domain,
+		MaxAge:   maxAge,
+		Secure:   s.Secure || r.TLS != nil,
+		HttpOnly: true,
+		SameSite: s.SameSite,
+	}
+	if cookie.Path == "" {
+		cookie.Path = defaultCookiePath
+	}
+	if cookie.SameSite == 0 {
+		cookie.SameSite = http.SameSiteLaxMode
+	}
+	if maxAge < 0 {
+		cookie.Expires = time.Unix(0, 0)
+	}
+	return cookie
+}
+
+// Namespace returns a copy of the store that persists the
+// state in a cookie with the namespace appended to the
+// cookie name.
+func (s *CookieStore) Namespace(name string) StateStore {
+	copy := *s
+	copy.Name = s.name() + name
+	return &copy
+}
+
+func (s *CookieStore) name() string {
+	if s.Name == "" {
+		return defaultCookieName
+	}
+	return s.Name
+}
+
+func (s *CookieStore) ttl() time.Duration {
+	if s.TTL == 0 {
+		return defaultStateTTL
+	}
+	return s.TTL
+}
+
+// MemoryStore is a StateStore that persists the state in
+// server memory. The client is associated with its state
+// using an opaque session identifier stored in a session
+// cookie. The zero value is ready to use.
+type MemoryStore struct {
+	// Cookie configures the session cookie that stores the
+	// session identifier. The cookie TTL is also used to
+	// expire the server-side state.
+	Cookie CookieStore
+
+	mu     sync.Mutex
+	states map[string]*memoryState
+}
+
+type memoryState struct {
+	state   *State
+	expires time.Time
+}
+
+// Create persists the state in memory and stores the
+// session identifier in the session cookie.
+func (s *MemoryStore) Create(w http.ResponseWriter, r *http.Request, state *State) error {
+	return s.create(w, r, state, &s.Cookie)
+}
+
+// Validate returns the state persisted in memory for the
+// session if it matches the value.
+func (s *MemoryStore) Validate(r *http.Request, value string) (*State, error) {
+	return s.validate(r, value, &s.Cookie)
+}
+
+// Delete removes the state persisted in memory for the
+// session and deletes the session cookie.
+func (s *MemoryStore) Delete(w http.ResponseWriter, r *http.Request) error {
+	return s.delete(w, r, &s.Cookie)
+}
+
+// Namespace returns a store that shares the server-side
+// state with s, but stores the session identifier in a
+// cookie with the namespace appended to the cookie name.
+func (s *MemoryStore) Namespace(name string) StateStore {
+	return &memoryNamespace{
+		store:  s,
+		cookie: s.Cookie.Namespace(name).(*CookieStore),
+	}
+}
+
+func (s *MemoryStore) create(w http.ResponseWriter, r *http.Request, state *State, cookie *CookieStore) error {
+	id, err := randomString()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+
+	s.mu.Lock()
+	if s.states == nil {
+		s.states = map[string]*memoryState{}
+	}
+	for k, v := range s.states {
+		if now.After(v.expires) {
+			delete(s.states, k)
+		}
+	}
+	s.states[id] = &memoryState{
+		state:   state,
+		expires: now.Add(cookie.ttl()),
+	}
+	s.mu.Unlock()
+
+	return cookie.Create(w, r, &State{Value: id})
+}
+
+func (s *MemoryStore) validate(r *http.Request, value string, cookie *CookieStore) (*State, error) {
+	session, err := cookie.read(r)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	v, ok := s.states[session.Value]
+	s.mu.Unlock()
+	if !ok || time.Now().After(v.expires) {
+		return nil, ErrState
+	}
+	if !equal(v.state.Value, value) {
+		return nil, ErrState
+	}
+	return v.state, nil
+}
+
+func (s *MemoryStore) delete(w http.ResponseWriter, r *http.Request, cookie *CookieStore) error {
+	if session, err := cookie.read(r); err == nil {
+		s.mu.Lock()
+		delete(s.states, session.Value)
+		s.mu.Unlock()
+	}
+	return cookie.Delete(w, r)
+}
+
+// memoryNamespace is a namespace of a MemoryStore.
+type memoryNamespace struct {
+	store  *MemoryStore
+	cookie *CookieStore
+}
+
+func (s *memoryNamespace) Create(w http.ResponseWriter, r *http.Request, state *State) error {
+	return s.store.create(w, r, state, s.cookie)
+}
+
+func (s *memoryNamespace) Validate(r *http.Request, value string) (*State, error) {
+	return s.store.validate(r, value, s.cookie)
+}
+
+func (s *memoryNamespace) Delete(w http.ResponseWriter, r *http.Request) error {
+	return s.store.delete(w, r, s.cookie)
+}
+
+// equal reports whether the strings are equal using a
+// constant time comparison.
+func equal(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// sign returns the HMAC-SHA256 signature of the value.
+func sign(key []byte, value string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(value))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// randomString returns a random, url-safe string.
+func randomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,313 @@
+// Copyright 2017 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package login
+
+import (
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCookieStore(t *testing.T) {
+	s := new(CookieStore)
+	state := &State{Value: "4d65822107fcfd52", Verifier: "dBjftJeZ4CVP"}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	if err := s.Create(w, r, state); err != nil {
+		t.Error(err)
+		return
+	}
+
+	cookie := w.Result().Cookies()[0]
+	if got, want := cookie.Name, "_oauth_state_"; got != want {
+		t.Errorf("Want cookie name %s, got %s", want, got)
+	}
+	header := w.Header().Get("Set-Cookie")
+	if want := "; Path=/; Max-Age=1800; HttpOnly; SameSite=Lax"; !strings.HasSuffix(header, want) {
+		t.Errorf("Want cookie attributes %s, got %s", want, header)
+	}
+
+	r = httptest.NewRequest("GET", "/", nil)
+	r.AddCookie(cookie)
+	got, err := s.Validate(r, "4d65822107fcfd52")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !reflect.DeepEqual(got, state) {
+		t.Errorf("Want state %v, got %v", state, got)
+	}
+}
+
+func TestCookieStoreOptions(t *testing.T) {
+	s := &CookieStore{
+		Name:     "_github_state_",
+		Path:     "/login",
+		Domain:   "company.com",
+		TTL:      time.Minute,
+		SameSite: http.SameSiteStrictMode,
+	}
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	r.TLS = new(tls.ConnectionState)
+	s.Create(w, r, &State{Value: "4d65822107fcfd52"})
+
+	header := w.Header().Get("Set-Cookie")
+	if want := "; Path=/login; Domain=company.com; Max-Age=60; HttpOnly; Secure; SameSite=Strict"; !strings.HasSuffix(header, want) {
+		t.Errorf("Want cookie attributes %s, got %s", want, header)
+	}
+	if !strings.HasPrefix(header, "_github_state_=") {
+		t.Errorf("Want custom cookie name, got %s", header)
+	}
+}
+
+func TestCookieStoreValidate(t *testing.T) {
+	s := new(CookieStore)
+	w := httptest.NewRecorder()
+	s.Create(w, httptest.NewRequest("GET", "/", nil), &State{Value: "4d65822107fcfd52"})
+	cookie := w.Result().Cookies()[0]
+
+	tests := []struct {
+		state  string
+		cookie *http.Cookie
+		err    error
+	}{
+		{
+			state:  "4d65822107fcfd52",
+			cookie: cookie,
+		},
+		{
+			state:  "0000000000000000",
+			cookie: cookie,
+			err:    ErrState,
+		},
+		{
+			state:  "4d65822107fcfd52",
+			cookie: &http.Cookie{Name: "_oauth_state_", Value: "4d65822107fcfd52"},
+			err:    ErrState,
+		},
+		{
+			state: "4d65822107fcfd52",
+			err:   ErrState,
+		},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest("GET", "/", nil)
+		if test.cookie != nil {
+			r.AddCookie(test.cookie)
+		}
+		if _, got := s.Validate(r, test.state); got != test.err {
+			t.Errorf("Want error %v, got %v", test.err, got)
+		}
+	}
+}
+
+func TestCookieStoreDelete(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	new(CookieStore).Delete(w, r)
+	c := "_oauth_state_=; Path=/; Expires=Thu, 01 Jan 1970 00:00:00 GMT; Max-Age=0; HttpOnly; SameSite=Lax"
+	if got, want := w.Header().Get("Set-Cookie"), c; got != want {
+		t.Errorf("Want cookie value %s, got %s", want, got)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	s := new(MemoryStore)
+	state := &State{Value: "4d65822107fcfd52", Verifier: "dBjftJeZ4CVP"}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	if err := s.Create(w, r, state); err != nil {
+		t.Error(err)
+		return
+	}
+	cookie := w.Result().Cookies()[0]
+	if strings.Contains(cookie.Value, "dBjftJeZ4CVP") {
+		t.Errorf("Expect state not stored in cookie")
+	}
+
+	r = httptest.NewRequest("GET", "/", nil)
+	r.AddCookie(cookie)
+	if _, err := s.Validate(r, "0000000000000000"); err != ErrState {
+		t.Errorf("Want error %v, got %v", ErrState, err)
+	}
+	got, err := s.Validate(r, "4d65822107fcfd52")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got != state {
+		t.Errorf("Want state %v, got %v", state, got)
+	}
+
+	s.Delete(httptest.NewRecorder(), r)
+	if _, err := s.Validate(r, "4d65822107fcfd52"); err != ErrState {
+		t.Errorf("Want deleted state rejected, got %v", err)
+	}
+}
+
+func TestMemoryStoreExpired(t *testing.T) {
+	s := &MemoryStore{Cookie: CookieStore{TTL: time.Nanosecond}}
+	w := httptest.NewRecorder()
+	s.Create(w, httptest.NewRequest("GET", "/", nil), &State{Value: "4d65822107fcfd52"})
+	time.Sleep(time.Millisecond)
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.AddCookie(w.Result().Cookies()[0])
+	if _, err := s.Validate(r, "4d65822107fcfd52"); err != ErrState {
+		t.Errorf("Want expired state rejected, got %v", err)
+	}
+}
+
+func TestCookieStoreSigned(t *testing.T) {
+	s := &CookieStore{Keys: [][]byte{[]byte("correct-horse-battery-staple")}}
+	w := httptest.NewRecorder()
+	s.Create(w, httptest.NewRequest("GET", "/", nil), &State{Value: "4d65822107fcfd52"})
+	cookie := w.Result().Cookies()[0]
+
+	parts := strings.Split(cookie.Value, ".")
+	if len(parts) != 2 {
+		t.Errorf("Want signed cookie value, got %s", cookie.Value)
+		return
+	}
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.AddCookie(cookie)
+	if _, err := s.Validate(r, "4d65822107fcfd52"); err != nil {
+		t.Errorf("Want signed state accepted, got %v", err)
+	}
+
+	// the cookie is rejected if the payload is modified.
+	forged, _ := json.Marshal(&cookieValue{
+		State:   State{Value: "0000000000000000"},
+		Expires: time.Now().Add(time.Hour).Unix(),
+	})
+	r = httptest.NewRequest("GET", "/", nil)
+	r.AddCookie(&http.Cookie{
+		Name:  "_oauth_state_",
+		Value: base64.RawURLEncoding.EncodeToString(forged) + "." + parts[1],
+	})
+	if _, err := s.Validate(r, "0000000000000000"); err != ErrState {
+		t.Errorf("Want tampered state rejected, got %v", err)
+	}
+
+	// the cookie is rejected if the signature is missing.
+	r = httptest.NewRequest("GET", "/", nil)
+	r.AddCookie(&http.Cookie{Name: "_oauth_state_", Value: parts[0]})
+	if _, err := s.Validate(r, "4d65822107fcfd52"); err != ErrState {
+		t.Errorf("Want unsigned state rejected, got %v", err)
+	}
+
+	// the cookie is rejected if signed with an unknown key.
+	other := &CookieStore{Keys: [][]byte{[]byte("Tr0ub4dor&3")}}
+	r = httptest.NewRequest("GET", "/", nil)
+	r.AddCookie(cookie)
+	if _, err := other.Validate(r, "4d65822107fcfd52"); err != ErrState {
+		t.Errorf("Want state signed with unknown key rejected, got %v", err)
+	}
+}
+
+func TestCookieStoreRotation(t *testing.T) {
+	prev := &CookieStore{Keys: [][]byte{[]byte("correct-horse-battery-staple")}}
+	w := httptest.NewRecorder()
+	prev.Create(w, httptest.NewRequest("GET", "/", nil), &State{Value: "4d65822107fcfd52"})
+
+	// the cookie signed with the previous key is accepted
+	// after a new key is added.
+	next := &CookieStore{Keys: [][]byte{
+		[]byte("Tr0ub4dor&3"),
+		[]byte("correct-horse-battery-staple"),
+	}}
+	r := httptest.NewRequest("GET", "/", nil)
+	r.AddCookie(w.Result().Cookies()[0])
+	if _, err := next.Validate(r, "4d65822107fcfd52"); err != nil {
+		t.Errorf("Want state signed with previous key accepted, got %v", err)
+	}
+}
+
+func TestCookieStoreExpired(t *testing.T) {
+	key := []byte("correct-horse-battery-staple")
+	s := &CookieStore{Keys: [][]byte{key}}
+
+	// the cookie is rejected if replayed after expiration,
+	// even if the client ignores the cookie max age.
+	data, _ := json.Marshal(&cookieValue{
+		State:   State{Value: "4d65822107fcfd52"},
+		Expires: time.Now().Add(-time.Minute).Unix(),
+	})
+	value := base64.RawURLEncoding.EncodeToString(data)
+	r := httptest.NewRequest("GET", "/", nil)
+	r.AddCookie(&http.Cookie{
+		Name:  "_oauth_state_",
+		Value: value + "." + sign(key, value),
+	})
+	if _, err := s.Validate(r, "4d65822107fcfd52"); err != ErrState {
+		t.Errorf("Want expired state rejected, got %v", err)
+	}
+}
+
+func TestCookieStoreNamespace(t *testing.T) {
+	s := new(CookieStore)
+	a := s.Namespace("github_com")
+	b := s.Namespace("github_example_com")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	a.Create(w, r, &State{Value: "4d65822107fcfd52"})
+	b.Create(w, r, &State{Value: "c2e1b0a5d7f94e38"})
+
+	cookies := w.Result().Cookies()
+	if len(cookies) != 2 {
+		t.Errorf("Want a cookie for each namespace, got %d", len(cookies))
+		return
+	}
+	if got, want := cookies[0].Name, "_oauth_state_github_com"; got != want {
+		t.Errorf("Want cookie name %s, got %s", want, got)
+	}
+
+	r = httptest.NewRequest("GET", "/", nil)
+	for _, cookie := range cookies {
+		r.AddCookie(cookie)
+	}
+	if _, err := a.Validate(r, "4d65822107fcfd52"); err != nil {
+		t.Errorf("Want state for namespace a, got error %v", err)
+	}
+	if _, err := b.Validate(r, "c2e1b0a5d7f94e38"); err != nil {
+		t.Errorf("Want state for namespace b, got error %v", err)
+	}
+	if _, err := b.Validate(r, "4d65822107fcfd52"); err != ErrState {
+		t.Errorf("Want state isolated between namespaces, got %v", err)
+	}
+}
+
+func TestMemoryStoreNamespace(t *testing.T) {
+	s := new(MemoryStore)
+	a := s.Namespace("github_com")
+	b := s.Namespace("github_example_com")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	a.Create(w, r, &State{Value: "4d65822107fcfd52"})
+	b.Create(w, r, &State{Value: "c2e1b0a5d7f94e38"})
+
+	r = httptest.NewRequest("GET", "/", nil)
+	for _, cookie := range w.Result().Cookies() {
+		r.AddCookie(cookie)
+	}
+	if _, err := a.Validate(r, "4d65822107fcfd52"); err != nil {
+		t.Errorf("Want state for namespace a, got error %v", err)
+	}
+	if _, err := b.Validate(r, "c2e1b0a5d7f94e38"); err != nil {
+		t.Errorf("Want state for namespace b, got error %v", err)
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright 2017 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package login
+
+import "net/http"
+
+// Transport is an http.RoundTripper that authorizes each
+// request with a token from the TokenSource.
+type Transport struct {
+	// Source supplies the token used to authorize each
+	// request.
+	Source *TokenSource
+
+	// Base is the underlying http.RoundTripper used to
+	// make requests. If nil, DefaultTransport is used.
+	Base http.RoundTripper
+}
+
+// RoundTrip authorizes and executes the http.Request.
+func (t *Transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	token, err := t.Source.Token(r.Context())
+	if err != nil {
+		if r.Body != nil {
+			r.Body.Close()
+		}
+		return nil, err
+	}
+	// the http.RoundTripper must not modify the request,
+	// so the request is cloned before the authorization
+	// header is added.
+	req := r.Clone(r.Context())
+	req.Header.Set("Authorization", "Bearer "+token.Access)
+	return t.base().RoundTrip(req)
+}
+
+func (t *Transport) base() http.RoundTripper {
+	if t.Base == nil {
+		return http.DefaultTransport
+	}
+	return t.Base
+}
+
+// NewClient returns an http.Client that authorizes each
+// request with a token from the TokenSource.
+func NewClient(s *TokenSource) *http.Client {
+	return &http.Client{
+		Transport: &Transport{Source: s},
+	}
+}
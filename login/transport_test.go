@@ -0,0 +1,55 @@
+// Copyright 2017 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package login
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTransport(t *testing.T) {
+	var header string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("Authorization")
+	}))
+	defer ts.Close()
+
+	token := &Token{
+		Access:  "755bb80e5b",
+		Refresh: "e08f3fa43e",
+		Expires: time.Now(),
+	}
+	client := NewClient(
+		NewTokenSource(new(mockRefresher), token, nil),
+	)
+
+	req, _ := http.NewRequest("GET", ts.URL, nil)
+	res, err := client.Do(req)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	res.Body.Close()
+
+	if got, want := header, "Bearer 9b2b4b1c7f"; got != want {
+		t.Errorf("Want authorization header %q, got %q", want, got)
+	}
+	if req.Header.Get("Authorization") != "" {
+		t.Errorf("Expect original request unmodified")
+	}
+}
+
+func TestTransportNilToken(t *testing.T) {
+	client := NewClient(
+		NewTokenSource(new(mockRefresher), nil, nil),
+	)
+	_, err := client.Get("http://localhost")
+	if !errors.Is(err, ErrToken) {
+		t.Errorf("Want error %s, got %v", ErrToken, err)
+	}
+}